@@ -11,6 +11,12 @@ import (
 
 	"github.com/aws/aws-sdk-go/service/inspector"
 	"github.com/aws/aws-sdk-go/service/inspector/inspectoriface"
+
+	"github.com/aws/aws-sdk-go/service/inspector2"
+	"github.com/aws/aws-sdk-go/service/inspector2/inspector2iface"
+
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
 )
 
 type MockSTSClient struct {
@@ -295,3 +301,191 @@ func (m *MockInspectorClient1) DescribeAssessmentTemplates(input *inspector.Desc
 	}, nil
 
 }
+
+type MockInspector2Client struct {
+	inspector2iface.Inspector2API
+	// inputs received by ListFindings
+	Inputs []*inspector2.ListFindingsInput
+}
+
+// ListFindings returns the findings over two pages
+func (m *MockInspector2Client) ListFindings(input *inspector2.ListFindingsInput) (*inspector2.ListFindingsOutput, error) {
+	m.Inputs = append(m.Inputs, input)
+	observedAt := ptrToTime(time.Date(2019, 05, 20, 10, 0, 0, 0, time.UTC))
+	if input.NextToken == nil {
+		return &inspector2.ListFindingsOutput{
+			Findings: []*inspector2.Finding{
+				{
+					AwsAccountId:    ptrToStr("012345678901"),
+					FindingArn:      ptrToStr("arn:aws:inspector2:eu-west-2:012345678901:finding/0001"),
+					Type:            ptrToStr(inspector2.FindingTypePackageVulnerability),
+					Severity:        ptrToStr(inspector2.SeverityCritical),
+					Status:          ptrToStr(inspector2.FindingStatusActive),
+					Title:           ptrToStr("CVE-2019-0001 - openssl"),
+					Description:     ptrToStr("A vulnerability in openssl."),
+					FixAvailable:    ptrToStr(inspector2.FixAvailableYes),
+					InspectorScore:  ptrToFloat64(9.8),
+					FirstObservedAt: observedAt,
+					LastObservedAt:  observedAt,
+					Remediation: &inspector2.Remediation{
+						Recommendation: &inspector2.Recommendation{Text: ptrToStr("Upgrade openssl.")},
+					},
+					PackageVulnerabilityDetails: &inspector2.PackageVulnerabilityDetails{
+						VulnerabilityId: ptrToStr("CVE-2019-0001"),
+						Source:          ptrToStr("NVD"),
+						Cvss: []*inspector2.CvssScore{
+							{BaseScore: ptrToFloat64(7.5), Version: ptrToStr("2.0"), Source: ptrToStr("NVD"), ScoringVector: ptrToStr("-")},
+							{BaseScore: ptrToFloat64(9.8), Version: ptrToStr("3.1"), Source: ptrToStr("NVD"), ScoringVector: ptrToStr("-")},
+						},
+						VulnerablePackages: []*inspector2.VulnerablePackage{
+							{Name: ptrToStr("openssl"), Version: ptrToStr("1.0.2k"), FixedInVersion: ptrToStr("1.0.2l")},
+						},
+					},
+					Resources: []*inspector2.Resource{
+						{
+							Id:   ptrToStr("i-0123456789abcdef0"),
+							Type: ptrToStr(inspector2.ResourceTypeAwsEc2Instance),
+							Tags: map[string]*string{"Name": ptrToStr("web-server"), "Environment": ptrToStr("prod")},
+							Details: &inspector2.ResourceDetails{
+								AwsEc2Instance: &inspector2.AwsEc2InstanceDetails{
+									ImageId:       ptrToStr("ami-0123456789abcdef0"),
+									IpV4Addresses: []*string{ptrToStr("10.0.0.1")},
+								},
+							},
+						},
+					},
+				},
+				{
+					AwsAccountId:    ptrToStr("012345678901"),
+					FindingArn:      ptrToStr("arn:aws:inspector2:eu-west-2:012345678901:finding/0002"),
+					Type:            ptrToStr(inspector2.FindingTypeNetworkReachability),
+					Severity:        ptrToStr(inspector2.SeverityMedium),
+					Status:          ptrToStr(inspector2.FindingStatusActive),
+					Title:           ptrToStr("Port 22 is reachable from an Internet Gateway"),
+					Description:     ptrToStr("On the instance, port range 22 - 22 is reachable from an Internet Gateway."),
+					FirstObservedAt: observedAt,
+					LastObservedAt:  observedAt,
+					Remediation:     &inspector2.Remediation{},
+					NetworkReachabilityDetails: &inspector2.NetworkReachabilityDetails{
+						Protocol:      ptrToStr(inspector2.NetworkProtocolTcp),
+						OpenPortRange: &inspector2.PortRange{Begin: ptrToInt64(22), End: ptrToInt64(22)},
+						NetworkPath: &inspector2.NetworkPath{
+							Steps: []*inspector2.Step{
+								{ComponentId: ptrToStr("igw-01234567"), ComponentType: ptrToStr("AWS::EC2::InternetGateway")},
+								{ComponentId: ptrToStr("sg-01234567"), ComponentType: ptrToStr("AWS::EC2::SecurityGroup")},
+							},
+						},
+					},
+					Resources: []*inspector2.Resource{
+						{
+							Id:   ptrToStr("i-0123456789abcdef0"),
+							Type: ptrToStr(inspector2.ResourceTypeAwsEc2Instance),
+							Tags: map[string]*string{"Name": ptrToStr("web-server")},
+						},
+					},
+				},
+			},
+			NextToken: ptrToStr("page2"),
+		}, nil
+	}
+	return &inspector2.ListFindingsOutput{
+		Findings: []*inspector2.Finding{
+			{
+				AwsAccountId:    ptrToStr("012345678901"),
+				FindingArn:      ptrToStr("arn:aws:inspector2:eu-west-2:012345678901:finding/0003"),
+				Type:            ptrToStr(inspector2.FindingTypePackageVulnerability),
+				Severity:        ptrToStr(inspector2.SeverityUntriaged),
+				Status:          ptrToStr(inspector2.FindingStatusActive),
+				Description:     ptrToStr("A vulnerability in requests."),
+				FixAvailable:    ptrToStr(inspector2.FixAvailableNo),
+				FirstObservedAt: observedAt,
+				LastObservedAt:  observedAt,
+				Remediation:     &inspector2.Remediation{},
+				PackageVulnerabilityDetails: &inspector2.PackageVulnerabilityDetails{
+					VulnerabilityId: ptrToStr("GHSA-0000-0000-0000"),
+					Source:          ptrToStr("GITHUB"),
+					VulnerablePackages: []*inspector2.VulnerablePackage{
+						{Name: ptrToStr("requests"), Version: ptrToStr("2.19.0"), FixedInVersion: ptrToStr("NotAvailable")},
+					},
+				},
+				Resources: []*inspector2.Resource{
+					{
+						Id:   ptrToStr("arn:aws:ecr:eu-west-2:012345678901:repository/app/sha256:0123"),
+						Type: ptrToStr(inspector2.ResourceTypeAwsEcrContainerImage),
+						Details: &inspector2.ResourceDetails{
+							AwsEcrContainerImage: &inspector2.AwsEcrContainerImageDetails{
+								RepositoryName: ptrToStr("app"),
+								ImageTags:      []*string{ptrToStr("v1.2.0")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// MockOrganizationsClient represents an organization with the following structure:
+//
+//	r-ab12: 111111111111 (prod), 444444444444 (closed, suspended)
+//	  ou-ab12-11111111: 222222222222 (dev)
+//	    ou-ab12-22222222: 333333333333 (staging)
+type MockOrganizationsClient struct {
+	organizationsiface.OrganizationsAPI
+}
+
+func (m *MockOrganizationsClient) ListAccounts(input *organizations.ListAccountsInput) (*organizations.ListAccountsOutput, error) {
+	if input.NextToken == nil {
+		return &organizations.ListAccountsOutput{
+			Accounts: []*organizations.Account{
+				{Id: ptrToStr("111111111111"), Name: ptrToStr("prod"), Status: ptrToStr(organizations.AccountStatusActive)},
+				{Id: ptrToStr("222222222222"), Name: ptrToStr("dev"), Status: ptrToStr(organizations.AccountStatusActive)},
+			},
+			NextToken: ptrToStr("page2"),
+		}, nil
+	}
+	return &organizations.ListAccountsOutput{
+		Accounts: []*organizations.Account{
+			{Id: ptrToStr("333333333333"), Name: ptrToStr("staging"), Status: ptrToStr(organizations.AccountStatusActive)},
+			{Id: ptrToStr("444444444444"), Name: ptrToStr("closed"), Status: ptrToStr(organizations.AccountStatusSuspended)},
+		},
+	}, nil
+}
+
+func (m *MockOrganizationsClient) ListAccountsForParent(input *organizations.ListAccountsForParentInput) (*organizations.ListAccountsForParentOutput, error) {
+	ids := map[string][]string{
+		"r-ab12":           {"111111111111", "444444444444"},
+		"ou-ab12-11111111": {"222222222222"},
+		"ou-ab12-22222222": {"333333333333"},
+	}[*input.ParentId]
+	var accounts []*organizations.Account
+	for _, id := range ids {
+		accounts = append(accounts, &organizations.Account{Id: ptrToStr(id)})
+	}
+	return &organizations.ListAccountsForParentOutput{Accounts: accounts}, nil
+}
+
+func (m *MockOrganizationsClient) ListOrganizationalUnitsForParent(input *organizations.ListOrganizationalUnitsForParentInput) (*organizations.ListOrganizationalUnitsForParentOutput, error) {
+	ids := map[string][]string{
+		"r-ab12":           {"ou-ab12-11111111"},
+		"ou-ab12-11111111": {"ou-ab12-22222222"},
+	}[*input.ParentId]
+	var ous []*organizations.OrganizationalUnit
+	for _, id := range ids {
+		ous = append(ous, &organizations.OrganizationalUnit{Id: ptrToStr(id)})
+	}
+	return &organizations.ListOrganizationalUnitsForParentOutput{OrganizationalUnits: ous}, nil
+}
+
+func (m *MockOrganizationsClient) ListTagsForResource(input *organizations.ListTagsForResourceInput) (*organizations.ListTagsForResourceOutput, error) {
+	tags := map[string]map[string]string{
+		"111111111111": {"environment": "prod", "inspector": "enabled"},
+		"222222222222": {"environment": "dev"},
+		"333333333333": {"environment": "staging", "inspector": "enabled"},
+	}[*input.ResourceId]
+	var out []*organizations.Tag
+	for k, v := range tags {
+		out = append(out, &organizations.Tag{Key: ptrToStr(k), Value: ptrToStr(v)})
+	}
+	return &organizations.ListTagsForResourceOutput{Tags: out}, nil
+}
@@ -14,7 +14,7 @@ func getAssumeRoleCreds(input getAssumeRoleCredsInput) (creds *credentials.Crede
 	if input.RoleArn != "" {
 		roleArn = input.RoleArn
 	} else {
-		roleArn = genRoleArn(input.AccountID, input.RoleName)
+		roleArn = genRoleArn(input.Partition, input.AccountID, input.RoleName)
 	}
 	// TODO: Test without external id specified
 	creds = stscreds.NewCredentials(input.Sess, roleArn, func(p *stscreds.AssumeRoleProvider) {
@@ -29,12 +29,16 @@ func getAssumeRoleCreds(input getAssumeRoleCredsInput) (creds *credentials.Crede
 
 type getAssumeRoleCredsInput struct {
 	Sess       *session.Session
+	Partition  string
 	AccountID  string
 	RoleArn    string
 	RoleName   string
 	ExternalID string
 }
 
-func genRoleArn(accountID, roleName string) string {
-	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, roleName)
+func genRoleArn(partition, accountID, roleName string) string {
+	if partition == "" {
+		partition = PartitionAWS
+	}
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountID, roleName)
 }
@@ -0,0 +1,100 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const byFindingSheetName = "By Finding"
+
+// findingGroup is a single finding, identified by title and rules package, and the resources it affects
+type findingGroup struct {
+	severity      string
+	title         string
+	packageName   string
+	accounts      []string
+	regions       []string
+	asgNames      []string
+	instanceIDs   []string
+	instanceNames []string
+}
+
+// appendUnique adds the value to the slice if it isn't already present, ignoring empty or placeholder values
+func appendUnique(in []string, value string) []string {
+	if value == "" || value == "-" || stringInSlice(value, in) {
+		return in
+	}
+	return append(in, value)
+}
+
+func groupFindings(data []accountSpreadsheetData) (groups []findingGroup) {
+	lookup := make(map[string]int)
+	for _, accountData := range data {
+		account := accountData.sheetName
+		for _, dr := range accountData.rows {
+			key := dr.packageName + "|" + dr.findingTitle
+			i, ok := lookup[key]
+			if !ok {
+				groups = append(groups, findingGroup{
+					severity:    dr.severity,
+					title:       dr.findingTitle,
+					packageName: dr.packageName,
+				})
+				i = len(groups) - 1
+				lookup[key] = i
+			}
+			g := &groups[i]
+			if severityRank[dr.severity] > severityRank[g.severity] {
+				g.severity = dr.severity
+			}
+			g.accounts = appendUnique(g.accounts, account)
+			g.regions = appendUnique(g.regions, dr.region)
+			g.asgNames = appendUnique(g.asgNames, dr.asgName)
+			g.instanceIDs = appendUnique(g.instanceIDs, dr.instanceID)
+			g.instanceNames = appendUnique(g.instanceNames, dr.instanceName)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if severityRank[groups[i].severity] != severityRank[groups[j].severity] {
+			return severityRank[groups[i].severity] > severityRank[groups[j].severity]
+		}
+		return len(groups[i].instanceIDs) > len(groups[j].instanceIDs)
+	})
+	return groups
+}
+
+func addByFindingSheet(xlsx *excelize.File, styles spreadsheetStyles, groups []findingGroup) {
+	sheetName := byFindingSheetName
+	_ = xlsx.NewSheet(sheetName)
+	_ = xlsx.SetSheetRow(sheetName, "A1", &[]interface{}{"SEVERITY", "RULES PACKAGE", "TITLE", "INSTANCES", "ACCOUNTS", "REGIONS", "ASGS", "INSTANCE IDS", "INSTANCE NAMES"})
+	_ = xlsx.SetCellStyle(sheetName, "A1", "I1", styles.header)
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 15)
+	_ = xlsx.SetColWidth(sheetName, "B", "B", 44)
+	_ = xlsx.SetColWidth(sheetName, "C", "C", 60)
+	_ = xlsx.SetColWidth(sheetName, "D", "D", 15)
+	_ = xlsx.SetColWidth(sheetName, "E", "G", 24)
+	_ = xlsx.SetColWidth(sheetName, "H", "I", 40)
+	row := 1
+	for _, g := range groups {
+		row++
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{
+			g.severity,
+			g.packageName,
+			g.title,
+			len(g.instanceIDs),
+			strings.Join(g.accounts, "\r\n"),
+			strings.Join(g.regions, "\r\n"),
+			strings.Join(g.asgNames, "\r\n"),
+			strings.Join(g.instanceIDs, "\r\n"),
+			strings.Join(g.instanceNames, "\r\n"),
+		})
+		if style := styles.severity(g.severity); style != 0 {
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), style)
+		}
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("I%d", row), styles.defaultCentered)
+	}
+	_ = xlsx.AutoFilter(sheetName, "A1", fmt.Sprintf("I%d", row), "")
+}
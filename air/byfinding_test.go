@@ -0,0 +1,25 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupFindings(t *testing.T) {
+	ar := testAccountsResults()
+	ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings = append(ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings,
+		testFinding("CVE-2019-0001", "High", "i-0000000004", "api"))
+	groups := groupFindings(generateSpreadsheetData(ar))
+
+	assert.Len(t, groups, 3)
+	assert.Equal(t, "CVE-2019-0001", groups[0].title)
+	assert.Equal(t, "HIGH", groups[0].severity)
+	assert.Equal(t, []string{"acme-nonprod", "acme-prod"}, groups[0].accounts)
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, groups[0].regions)
+	assert.Equal(t, []string{"i-0000000001", "i-0000000002", "i-0000000004"}, groups[0].instanceIDs)
+	assert.Equal(t, []string{"web", "api"}, groups[0].instanceNames)
+	assert.Empty(t, groups[0].asgNames)
+	assert.Equal(t, "MEDIUM", groups[1].severity)
+	assert.Equal(t, "LOW", groups[2].severity)
+}
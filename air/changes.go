@@ -0,0 +1,78 @@
+package air
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const changesSheetName = "Changes since last report"
+
+// addChangesSheet lists the findings that are new since the previous run, followed by those that have been resolved
+func addChangesSheet(xlsx *excelize.File, styles spreadsheetStyles, changes findingChanges) {
+	sheetName := changesSheetName
+	_ = xlsx.NewSheet(sheetName)
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 24)
+	_ = xlsx.SetColWidth(sheetName, "B", "B", 15)
+	_ = xlsx.SetColWidth(sheetName, "C", "C", 24)
+	_ = xlsx.SetColWidth(sheetName, "D", "D", 13.5)
+	_ = xlsx.SetColWidth(sheetName, "E", "F", 19)
+	_ = xlsx.SetColWidth(sheetName, "G", "G", 44)
+	_ = xlsx.SetColWidth(sheetName, "H", "H", 60)
+	_ = xlsx.SetColWidth(sheetName, "I", "I", 22.5)
+
+	previous := "no previous report"
+	if !changes.previous.IsZero() {
+		previous = changes.previous.UTC().Format(time.ANSIC) + " UTC"
+	}
+	summary := [][]interface{}{
+		{"PREVIOUS REPORT", previous},
+		{findingStatusNew, len(changes.new)},
+		{findingStatusPersisting, changes.persisting},
+		{findingStatusResolved, len(changes.resolved)},
+	}
+	row := 0
+	for _, s := range summary {
+		row++
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &s)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), styles.bold)
+	}
+
+	row += 2
+	headerRow := row
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"STATUS", "SEVERITY", "ACCOUNT", "REGION", "INSTANCE ID", "INSTANCE NAME", "RULES PACKAGE", "TITLE", "FIRST SEEN"})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("I%d", row), styles.header)
+	for _, group := range []struct {
+		status   string
+		findings []stateFinding
+	}{
+		{findingStatusNew, changes.new},
+		{findingStatusResolved, changes.resolved},
+	} {
+		for _, sf := range group.findings {
+			row++
+			account := sf.AccountAlias
+			if account == "" {
+				account = sf.AccountID
+			}
+			_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{
+				group.status,
+				sf.Severity,
+				account,
+				sf.Region,
+				sf.AgentID,
+				sf.InstanceName,
+				sf.RulesPackage,
+				sf.Title,
+				sf.FirstSeen.Format(time.ANSIC),
+			})
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), styles.bold)
+			if style := styles.severity(sf.Severity); style != 0 {
+				_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), style)
+			}
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("F%d", row), styles.defaultCentered)
+		}
+	}
+	_ = xlsx.AutoFilter(sheetName, fmt.Sprintf("A%d", headerRow), fmt.Sprintf("I%d", row), "")
+}
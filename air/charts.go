@@ -0,0 +1,70 @@
+package air
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	chartWidth  = 640
+	chartHeight = 360
+)
+
+type chartSeries struct {
+	Name       string `json:"name,omitempty"`
+	Categories string `json:"categories"`
+	Values     string `json:"values"`
+}
+
+type chartTitle struct {
+	Name string `json:"name"`
+}
+
+type chartLegend struct {
+	Position string `json:"position"`
+}
+
+type chartDimension struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type chartPlotArea struct {
+	ShowPercent bool `json:"show_percent"`
+	ShowVal     bool `json:"show_val"`
+}
+
+type chart struct {
+	Type      string         `json:"type"`
+	Series    []chartSeries  `json:"series"`
+	Title     chartTitle     `json:"title"`
+	Legend    chartLegend    `json:"legend"`
+	Dimension chartDimension `json:"dimension"`
+	PlotArea  chartPlotArea  `json:"plotarea"`
+}
+
+// chartRange returns an absolute reference to a range of cells, e.g. 'Summary'!$A$2:$A$4
+func chartRange(sheetName string, col int, firstRow, lastRow int) string {
+	colName, _ := excelize.ColumnNumberToName(col)
+	if firstRow == lastRow {
+		return fmt.Sprintf("'%s'!$%s$%d", sheetName, colName, firstRow)
+	}
+	return fmt.Sprintf("'%s'!$%s$%d:$%s$%d", sheetName, colName, firstRow, colName, lastRow)
+}
+
+func addChart(xlsx *excelize.File, sheetName, cell string, c chart) error {
+	if c.Dimension.Width == 0 {
+		c.Dimension = chartDimension{Width: chartWidth, Height: chartHeight}
+	}
+	if c.Legend.Position == "" {
+		c.Legend.Position = "bottom"
+	}
+	format, err := json.Marshal(c)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return xlsx.AddChart(sheetName, cell, string(format))
+}
@@ -0,0 +1,12 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChartRange(t *testing.T) {
+	assert.Equal(t, "'Summary'!$B$1", chartRange("Summary", 2, 1, 1))
+	assert.Equal(t, "'Summary'!$A$2:$A$4", chartRange("Summary", 1, 2, 4))
+}
@@ -0,0 +1,116 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Column defines a field to show on the account sheets of the spreadsheet
+// Header and Width are optional and default to the field's standard values
+type Column struct {
+	Field  string  `yaml:"field"`
+	Header string  `yaml:"header"`
+	Width  float64 `yaml:"width"`
+}
+
+type columnDefinition struct {
+	header   string
+	width    float64
+	centered bool
+	value    func(dr dataRow) interface{}
+}
+
+var columnDefinitions = map[string]columnDefinition{
+	"severity":          {header: "SEVERITY", width: 15, value: func(dr dataRow) interface{} { return dr.severity }},
+	"numeric-severity":  {header: "NUMERIC SEVERITY", width: 20, centered: true, value: func(dr dataRow) interface{} { return dr.numericSeverity }},
+	"region":            {header: "REGION", width: 13.5, centered: true, value: func(dr dataRow) interface{} { return dr.region }},
+	"template":          {header: "TEMPLATE", width: 26, value: func(dr dataRow) interface{} { return dr.templateName }},
+	"template-arn":      {header: "TEMPLATE ARN", width: 60, value: func(dr dataRow) interface{} { return dr.template }},
+	"run-arn":           {header: "RUN ARN", width: 60, value: func(dr dataRow) interface{} { return dr.runArn }},
+	"date":              {header: "DATE", width: 22.5, value: func(dr dataRow) interface{} { return dr.createdAt.Format(time.ANSIC) }},
+	"instance-id":       {header: "INSTANCE ID", width: 19, centered: true, value: func(dr dataRow) interface{} { return dr.instanceID }},
+	"instance-name":     {header: "INSTANCE NAME", width: 24, centered: true, value: func(dr dataRow) interface{} { return dr.instanceName }},
+	"ami-id":            {header: "AMI ID", width: 22, centered: true, value: func(dr dataRow) interface{} { return dr.amiID }},
+	"hostname":          {header: "HOSTNAME", width: 40, value: func(dr dataRow) interface{} { return dr.hostname }},
+	"ip-addresses":      {header: "IP ADDRESSES", width: 18, centered: true, value: func(dr dataRow) interface{} { return strings.Join(dr.ipAddresses, "\r\n") }},
+	"asg":               {header: "ASG", width: 20, centered: true, value: func(dr dataRow) interface{} { return dr.asgName }},
+	"rules-package":     {header: "RULES PACKAGE", width: 44, value: func(dr dataRow) interface{} { return dr.packageName }},
+	"rules-package-arn": {header: "RULES PACKAGE ARN", width: 60, value: func(dr dataRow) interface{} { return dr.packageArn }},
+	"title":             {header: "TITLE", width: 60, value: func(dr dataRow) interface{} { return dr.findingTitle }},
+	"finding-arn":       {header: "FINDING ARN", width: 60, value: func(dr dataRow) interface{} { return dr.findingArn }},
+	"confidence":        {header: "CONFIDENCE", width: 15, centered: true, value: func(dr dataRow) interface{} { return dr.confidence }},
+	"description":       {header: "DESCRIPTION", width: 70, value: func(dr dataRow) interface{} { return dr.description }},
+	"recommendation":    {header: "RECOMMENDATION", width: 150, value: func(dr dataRow) interface{} { return dr.recommendation }},
+	"comment":           {header: "COMMENT", width: 60, value: func(dr dataRow) interface{} { return dr.comment }},
+	"days-open":         {header: "DAYS OPEN", width: 13, centered: true, value: func(dr dataRow) interface{} { return dr.daysOpen }},
+	"sla":               {header: "SLA STATUS", width: 16, value: func(dr dataRow) interface{} { return dr.slaStatus }},
+	"status":            {header: "STATUS", width: 15, centered: true, value: func(dr dataRow) interface{} { return dr.status }},
+	"resource-type":     {header: "RESOURCE TYPE", width: 26, centered: true, value: func(dr dataRow) interface{} { return dr.resourceType }},
+	"vulnerability-id":  {header: "VULNERABILITY ID", width: 20, centered: true, value: func(dr dataRow) interface{} { return dr.vulnerabilityID }},
+	"cvss":              {header: "CVSS", width: 10, centered: true, value: func(dr dataRow) interface{} { return dr.cvssScore }},
+	"fix-available":     {header: "FIX AVAILABLE", width: 16, centered: true, value: func(dr dataRow) interface{} { return dr.fixAvailable }},
+	"packages":          {header: "PACKAGES", width: 50, value: func(dr dataRow) interface{} { return strings.Join(dr.packages, "\r\n") }},
+	"open-ports":        {header: "OPEN PORTS", width: 16, centered: true, value: func(dr dataRow) interface{} { return dr.openPorts }},
+	"network-path":      {header: "NETWORK PATH", width: 60, value: func(dr dataRow) interface{} { return dr.networkPath }},
+	"first-seen": {header: "FIRST SEEN", width: 22.5, value: func(dr dataRow) interface{} {
+		if dr.firstSeen.IsZero() {
+			return ""
+		}
+		return dr.firstSeen.Format(time.ANSIC)
+	}},
+	"suppression-expired": {header: "SUPPRESSION EXPIRED", width: 40, value: func(dr dataRow) interface{} {
+		if dr.expiredFilter == nil {
+			return ""
+		}
+		return dr.expiredFilter.describeExpiry()
+	}},
+}
+
+// defaultColumns are used when no columns are specified in the report configuration
+var defaultColumns = []Column{
+	{Field: "severity"},
+	{Field: "region"},
+	{Field: "template"},
+	{Field: "date"},
+	{Field: "instance-id"},
+	{Field: "instance-name"},
+	{Field: "asg"},
+	{Field: "rules-package"},
+	{Field: "title"},
+	{Field: "description"},
+	{Field: "recommendation"},
+}
+
+// columnFields returns the names of all fields that can be shown, in alphabetical order
+func columnFields() (fields []string) {
+	for field := range columnDefinitions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// resolveColumns returns the columns to show, with default headers and widths set where not specified
+func resolveColumns(columns []Column) (resolved []Column, err error) {
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+	for _, c := range columns {
+		field := strings.ToLower(strings.TrimSpace(c.Field))
+		definition, ok := columnDefinitions[field]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet column field '%s' not supported, valid fields are: %s", c.Field, strings.Join(columnFields(), ", "))
+		}
+		c.Field = field
+		if c.Header == "" {
+			c.Header = definition.header
+		}
+		if c.Width == 0 {
+			c.Width = definition.width
+		}
+		resolved = append(resolved, c)
+	}
+	return resolved, err
+}
@@ -0,0 +1,40 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveColumnsDefault(t *testing.T) {
+	columns, err := resolveColumns(nil)
+	assert.NoError(t, err)
+	assert.Len(t, columns, 11)
+	assert.Equal(t, Column{Field: "severity", Header: "SEVERITY", Width: 15}, columns[0])
+	assert.Equal(t, Column{Field: "recommendation", Header: "RECOMMENDATION", Width: 150}, columns[10])
+}
+
+func TestResolveColumnsCustom(t *testing.T) {
+	columns, err := resolveColumns([]Column{
+		{Field: "title", Header: "FINDING"},
+		{Field: "AMI-ID", Width: 30},
+		{Field: "finding-arn"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Column{
+		{Field: "title", Header: "FINDING", Width: 60},
+		{Field: "ami-id", Header: "AMI ID", Width: 30},
+		{Field: "finding-arn", Header: "FINDING ARN", Width: 60},
+	}, columns)
+}
+
+func TestResolveColumnsUnknownField(t *testing.T) {
+	_, err := resolveColumns([]Column{{Field: "unknown"}})
+	assert.Error(t, err)
+}
+
+func TestColumnDefinitionsDefaultsExist(t *testing.T) {
+	for _, c := range defaultColumns {
+		assert.Contains(t, columnFields(), c.Field)
+	}
+}
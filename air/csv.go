@@ -0,0 +1,90 @@
+package air
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var csvHeader = []string{
+	"ACCOUNT ID",
+	"ACCOUNT ALIAS",
+	"SEVERITY",
+	"REGION",
+	"TEMPLATE",
+	"TEMPLATE ARN",
+	"DATE",
+	"INSTANCE ID",
+	"INSTANCE NAME",
+	"AMI ID",
+	"ASG",
+	"RULES PACKAGE",
+	"RULES PACKAGE ARN",
+	"TITLE",
+	"DESCRIPTION",
+	"RECOMMENDATION",
+	"COMMENT",
+	// only reported by Inspector v2
+	"RESOURCE TYPE",
+	"VULNERABILITY ID",
+	"CVSS",
+	"FIX AVAILABLE",
+	"PACKAGES",
+}
+
+func generateCSV(accountsResults accountsResults, outputDir string, timeStamp time.Time) (string, error) {
+	path := getReportPath(outputDir, timeStamp, FormatCSV)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err = w.Write(csvHeader); err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, accountResults := range accountsResults {
+		for _, dr := range generateAccountRegionXLSXData(accountResults) {
+			record := []string{
+				accountResults.accountID,
+				accountResults.accountAlias,
+				dr.severity,
+				dr.region,
+				dr.templateName,
+				dr.template,
+				dr.createdAt.UTC().Format(time.RFC3339),
+				dr.instanceID,
+				dr.instanceName,
+				dr.amiID,
+				dr.asgName,
+				dr.packageName,
+				dr.packageArn,
+				dr.findingTitle,
+				dr.description,
+				dr.recommendation,
+				dr.comment,
+				dr.resourceType,
+				dr.vulnerabilityID,
+				dr.cvssScore,
+				dr.fixAvailable,
+				strings.Join(dr.packages, "; "),
+			}
+			if err = w.Write(record); err != nil {
+				return "", errors.WithStack(err)
+			}
+		}
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	absPath, _ := filepath.Abs(path)
+	fmt.Println("report written to:", absPath)
+	return absPath, err
+}
@@ -0,0 +1,36 @@
+package air
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path, err := generateCSV(testAccountsResults(), dir, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 5)
+	assert.Equal(t, csvHeader, records[0])
+	for _, record := range records {
+		assert.Len(t, record, len(csvHeader))
+	}
+	assert.Equal(t, "012345678901", records[1][0])
+	assert.Equal(t, "acme-nonprod", records[1][1])
+	assert.Equal(t, "HIGH", records[1][2])
+	assert.Equal(t, "987654321098", records[4][0])
+	assert.Equal(t, "CVE-2019-0002", records[4][13])
+}
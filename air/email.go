@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -89,7 +90,7 @@ func validateEmailSettings(email Email) (err error) {
 	}
 	return err
 }
-func emailReport(sess *session.Session, reportPath string, email Email, deleteAfter bool) (err error) {
+func emailReport(sess *session.Session, reportPaths []string, email Email, deleteAfter bool) (err error) {
 	err = validateEmailSettings(email)
 	if err != nil {
 		return
@@ -105,10 +106,23 @@ func emailReport(sess *session.Session, reportPath string, email Email, deleteAf
 	}
 
 	msg.SetHeader("Subject", emailSubject)
-	// TODO: Add summary to email body
+	// use the html report as the body, if one was generated
 	body := "attached"
+	for _, reportPath := range reportPaths {
+		if strings.HasSuffix(reportPath, "."+FormatHTML) {
+			var content []byte
+			content, err = ioutil.ReadFile(reportPath)
+			if err != nil {
+				err = errors.WithStack(err)
+				return
+			}
+			body = string(content)
+		}
+	}
 	msg.SetBody("text/html", body)
-	msg.Attach(reportPath)
+	for _, reportPath := range reportPaths {
+		msg.Attach(reportPath)
+	}
 
 	var emailRaw bytes.Buffer
 	_, err = msg.WriteTo(&emailRaw)
@@ -129,7 +143,7 @@ func emailReport(sess *session.Session, reportPath string, email Email, deleteAf
 		input := ses.SendRawEmailInput{Source: source, Destinations: destinations, RawMessage: &message}
 		_, err = svc.SendRawEmail(&input)
 		if err != nil {
-			delErr := deleteFile(reportPath)
+			delErr := deleteFiles(reportPaths)
 			if delErr != nil {
 				origErr := err.Error()
 				err = errors.New(origErr + "\nAdditionally, the report file could not be deleted.")
@@ -149,7 +163,7 @@ func emailReport(sess *session.Session, reportPath string, email Email, deleteAf
 		dialer.TLSConfig = tlsConfig
 		err = dialer.DialAndSend(msg)
 		if err != nil {
-			delErr := deleteFile(reportPath)
+			delErr := deleteFiles(reportPaths)
 			if delErr != nil {
 				err = errors.WithStack(delErr)
 				return
@@ -158,7 +172,7 @@ func emailReport(sess *session.Session, reportPath string, email Email, deleteAf
 		}
 	}
 	if deleteAfter {
-		err = deleteFile(reportPath)
+		err = deleteFiles(reportPaths)
 	}
 	return err
 }
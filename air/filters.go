@@ -1,29 +1,164 @@
 package air
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/inspector"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
 )
 
+// filter sets the severity and comment of findings matching all of the criteria specified
+// each criterion is a regular expression, with tag and attribute criteria in the form: <key regexp>=<value regexp>
 type filter struct {
-	TitleMatch string `yaml:"title-match"`
-	Severity   string `yaml:"severity"`
-	Comment    string `yaml:"comment"`
+	TitleMatch        string   `yaml:"title-match"`
+	RulesPackageMatch string   `yaml:"rules-package-match"`
+	RegionMatch       string   `yaml:"region-match"`
+	AccountMatch      string   `yaml:"account-match"`
+	TemplateMatch     string   `yaml:"template-match"`
+	ASGMatch          string   `yaml:"asg-match"`
+	AMIMatch          string   `yaml:"ami-match"`
+	TagMatch          []string `yaml:"tag-match"`
+	AttributeMatch    []string `yaml:"attribute-match"`
+	Severity          string   `yaml:"severity"`
+	Comment           string   `yaml:"comment"`
+	// date (YYYY-MM-DD) after which the filter no longer applies
+	Expires string `yaml:"expires"`
+	Owner   string `yaml:"owner"`
+	Ticket  string `yaml:"ticket"`
+}
+
+const filterExpiresLayout = "2006-01-02"
+
+// expiry returns the time the filter stops applying, which is the end of the day specified
+func (f filter) expiry() (expiry time.Time, err error) {
+	if f.Expires == "" {
+		return
+	}
+	expiry, err = time.Parse(filterExpiresLayout, f.Expires)
+	if err != nil {
+		err = fmt.Errorf("invalid expires value '%s', expected format: YYYY-MM-DD", f.Expires)
+		return
+	}
+	return expiry.AddDate(0, 0, 1), err
+}
+
+// expired returns true if the filter's expiry date has passed
+// filters with an invalid expiry date are considered expired so that findings are not hidden
+func (f filter) expired(now time.Time) bool {
+	expiry, err := f.expiry()
+	if err != nil {
+		return true
+	}
+	return !expiry.IsZero() && !now.Before(expiry)
+}
+
+// expiringFilters returns the filters that have expired, or will expire within the number of days specified
+func expiringFilters(filters filters, now time.Time, days int) (expiring filters) {
+	for _, f := range filters {
+		if f.Expires == "" {
+			continue
+		}
+		expiry, err := f.expiry()
+		if err != nil || expiry.Before(now.AddDate(0, 0, days)) {
+			expiring = append(expiring, f)
+		}
+	}
+	return expiring
+}
+
+// describeExpiry returns the expiry date along with the owner and ticket if specified
+func (f filter) describeExpiry() string {
+	description := f.Expires
+	var details []string
+	if f.Owner != "" {
+		details = append(details, "owner: "+f.Owner)
+	}
+	if f.Ticket != "" {
+		details = append(details, "ticket: "+f.Ticket)
+	}
+	if len(details) > 0 {
+		description += " (" + strings.Join(details, ", ") + ")"
+	}
+	return description
+}
+
+// criteria returns the filter's criteria in the form they are specified in the filters file
+func (f filter) criteria() (out []string) {
+	for _, c := range []struct{ name, value string }{
+		{"title-match", f.TitleMatch},
+		{"rules-package-match", f.RulesPackageMatch},
+		{"region-match", f.RegionMatch},
+		{"account-match", f.AccountMatch},
+		{"template-match", f.TemplateMatch},
+		{"asg-match", f.ASGMatch},
+		{"ami-match", f.AMIMatch},
+	} {
+		if c.value != "" {
+			out = append(out, fmt.Sprintf("%s: %s", c.name, c.value))
+		}
+	}
+	for _, t := range f.TagMatch {
+		out = append(out, "tag-match: "+t)
+	}
+	for _, a := range f.AttributeMatch {
+		out = append(out, "attribute-match: "+a)
+	}
+	return out
+}
+
+// findingContext holds the details of where a finding was reported, as they are not part of the finding itself
+type findingContext struct {
+	accountID    string
+	accountAlias string
+	region       string
+	templateName string
 }
 
 type filters []filter
 
 func parseFiltersFileContent(content []byte) (filters filters, err error) {
-	err = errors.WithStack(yaml.Unmarshal(content, &filters))
+	err = parseConfigContent(content, &filters)
 	return
 }
 
-func (ar *accountsResults) filter(filters filters) {
-	if filterMaps == nil {
-		filterMaps = make(map[string]*regexp.Regexp)
+// filterUsage records the number of findings a filter was applied to in each account
+type filterUsage struct {
+	filter   filter
+	accounts map[string]int
+	total    int
+	// number of findings the filter matched but no longer applies to as it has expired
+	expired int
+}
+
+type filterUsages []filterUsage
+
+// unused returns the filters that did not match any findings
+func (fus filterUsages) unused() (unused filters) {
+	for _, fu := range fus {
+		if fu.total == 0 && fu.expired == 0 {
+			unused = append(unused, fu.filter)
+		}
+	}
+	return unused
+}
+
+// expiredMatches returns the usage of the filters that have expired but still match findings
+func (fus filterUsages) expiredMatches() (expired filterUsages) {
+	for _, fu := range fus {
+		if fu.expired > 0 {
+			expired = append(expired, fu)
+		}
+	}
+	return expired
+}
+
+func (ar *accountsResults) filter(filters filters) (usage filterUsages) {
+	usage = make(filterUsages, len(filters))
+	for i, f := range filters {
+		usage[i] = filterUsage{filter: f, accounts: make(map[string]int)}
 	}
 	var filteredResults accountsResults
 	for _, res := range *ar {
@@ -39,7 +174,19 @@ func (ar *accountsResults) filter(filters filters) {
 					filteredRun := run
 					var filteredFindings findings
 					for _, f := range run.findings {
-						filteredFinding := filterFinding(f, filters)
+						filteredFinding, matched, expired := filterFinding(f, findingContext{
+							accountID:    res.accountID,
+							accountAlias: res.accountAlias,
+							region:       rres.region,
+							templateName: rtr.templateName,
+						}, filters)
+						if matched >= 0 {
+							usage[matched].accounts[res.accountID]++
+							usage[matched].total++
+						}
+						if expired >= 0 {
+							usage[expired].expired++
+						}
 						filteredFindings = append(filteredFindings, filteredFinding)
 					}
 					filteredRun.findings = filteredFindings
@@ -56,43 +203,233 @@ func (ar *accountsResults) filter(filters filters) {
 		filteredResults = append(filteredResults, filteredResult)
 	}
 	*ar = filteredResults
+	return usage
 }
 
 var filterMaps map[string]*regexp.Regexp
 
-func getCompiledRegex(regexString string) *regexp.Regexp {
+func getCompiledRegex(regexString string) (*regexp.Regexp, error) {
+	if filterMaps == nil {
+		filterMaps = make(map[string]*regexp.Regexp)
+	}
 	for k, v := range filterMaps {
 		if k == regexString {
-			return v
+			return v, nil
 		}
 	}
-	newRegex := regexp.MustCompile(regexString)
+	newRegex, err := regexp.Compile(regexString)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	filterMaps[regexString] = newRegex
-	return newRegex
+	return newRegex, nil
+}
+
+// filterPattern is a regular expression used by a filter along with the key it is specified by
+type filterPattern struct {
+	key        string
+	expression string
 }
 
-func filterFinding(finding finding, filters filters) (out finding) {
+// patterns returns the filter's regular expressions, with tag and attribute criteria split into key and value
+func (f filter) patterns() (out []filterPattern) {
+	for _, p := range []filterPattern{
+		{"title-match", f.TitleMatch},
+		{"rules-package-match", f.RulesPackageMatch},
+		{"region-match", f.RegionMatch},
+		{"account-match", f.AccountMatch},
+		{"template-match", f.TemplateMatch},
+		{"asg-match", f.ASGMatch},
+		{"ami-match", f.AMIMatch},
+	} {
+		if p.expression != "" {
+			out = append(out, p)
+		}
+	}
+	for _, kvs := range []struct {
+		key      string
+		criteria []string
+	}{{"tag-match", f.TagMatch}, {"attribute-match", f.AttributeMatch}} {
+		for _, c := range kvs.criteria {
+			key, value := splitKeyValueMatch(c)
+			out = append(out, filterPattern{kvs.key, key}, filterPattern{kvs.key, value})
+		}
+	}
+	return out
+}
+
+// invalidPatterns returns an error for each of the filter's expressions that cannot be compiled
+// a filter with an invalid expression never matches
+func (f filter) invalidPatterns() (errs []error) {
+	for _, p := range f.patterns() {
+		if _, err := regexp.Compile(p.expression); err != nil {
+			errs = append(errs, fmt.Errorf("invalid regular expression in %s: %s", p.key, err))
+		}
+	}
+	return errs
+}
+
+// splitKeyValueMatch splits a tag or attribute criterion into its key and value expressions
+func splitKeyValueMatch(in string) (key, value string) {
+	parts := strings.SplitN(in, "=", 2)
+	key = parts[0]
+	if len(parts) == 2 {
+		value = parts[1]
+	}
+	return
+}
+
+func matchesKeyValues(criteria []string, pairs map[string]string) bool {
+	for _, criterion := range criteria {
+		keyMatch, valueMatch := splitKeyValueMatch(criterion)
+		keyRegex, err := getCompiledRegex(keyMatch)
+		if err != nil {
+			return false
+		}
+		valueRegex, err := getCompiledRegex(valueMatch)
+		if err != nil {
+			return false
+		}
+		var found bool
+		for k, v := range pairs {
+			if keyRegex.MatchString(k) && valueRegex.MatchString(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func derefStr(in *string) string {
+	if in == nil {
+		return ""
+	}
+	return *in
+}
+
+// stringCriterion is met if the expression matches any of the values
+type stringCriterion struct {
+	match  string
+	values []string
+}
+
+// matches returns true if the finding meets all of the filter's criteria
+// a filter without any criteria matches nothing
+func (f filter) matches(finding finding, ctx findingContext) bool {
+	var asgName, amiID string
+	if finding.AssetAttributes != nil {
+		asgName = derefStr(finding.AssetAttributes.AutoScalingGroup)
+		amiID = derefStr(finding.AssetAttributes.AmiId)
+	}
+	stringCriteria := []stringCriterion{
+		{f.TitleMatch, []string{derefStr(finding.Title)}},
+		{f.RulesPackageMatch, []string{finding.rulePackageName}},
+		{f.RegionMatch, []string{ctx.region}},
+		{f.AccountMatch, []string{ctx.accountID, ctx.accountAlias}},
+		{f.TemplateMatch, []string{ctx.templateName}},
+		{f.ASGMatch, []string{asgName}},
+		{f.AMIMatch, []string{amiID}},
+	}
+
+	var criteria int
+	for _, sc := range stringCriteria {
+		if sc.match == "" {
+			continue
+		}
+		criteria++
+		r, err := getCompiledRegex(sc.match)
+		if err != nil {
+			return false
+		}
+		var matched bool
+		for _, v := range sc.values {
+			if r.MatchString(v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.TagMatch) > 0 {
+		criteria++
+		tags := make(map[string]string)
+		if finding.AssetAttributes != nil {
+			for _, t := range finding.AssetAttributes.Tags {
+				tags[derefStr(t.Key)] = derefStr(t.Value)
+			}
+		}
+		if !matchesKeyValues(f.TagMatch, tags) {
+			return false
+		}
+	}
+
+	if len(f.AttributeMatch) > 0 {
+		criteria++
+		attributes := make(map[string]string)
+		for _, a := range finding.Attributes {
+			attributes[derefStr(a.Key)] = derefStr(a.Value)
+		}
+		if !matchesKeyValues(f.AttributeMatch, attributes) {
+			return false
+		}
+	}
+
+	return criteria > 0
+}
+
+// filterFinding applies the first matching filter to the finding and returns the index of that filter, or -1 if none applied
+// the index of the first expired filter that matched is also returned, or -1 if none did
+func filterFinding(finding finding, ctx findingContext, filters filters) (out finding, matched, expired int) {
 	out = finding
-	for _, f := range filters {
-		if f.TitleMatch != "" {
-			r := getCompiledRegex(f.TitleMatch)
-			if r.MatchString(*finding.Title) {
-				out.Severity = ptrToStr(f.Severity)
-				out.comment = f.Comment
-				return out
+	expired = -1
+	now := time.Now()
+	for i, f := range filters {
+		if f.matches(finding, ctx) {
+			matched := f
+			// an expired filter no longer applies, but the finding is flagged in case another filter doesn't match
+			if f.expired(now) {
+				if out.expiredFilter == nil {
+					out.expiredFilter = &matched
+					expired = i
+				}
+				continue
 			}
+			out.originalSeverity = derefStr(finding.Severity)
+			out.Severity = ptrToStr(f.Severity)
+			out.comment = f.Comment
+			out.matchedFilter = &matched
+			out.expiredFilter = nil
+			return out, i, expired
 		}
 	}
-	return finding
+	if out.expiredFilter != nil {
+		out.comment = fmt.Sprintf("suppression expired %s", out.expiredFilter.describeExpiry())
+	}
+	return out, -1, expired
 }
 
 type finding struct {
 	inspector.Finding
 	rulePackageName string
 	comment         string
+	matchedFilter   *filter
+	expiredFilter   *filter
+	// severity reported by Inspector, only set if changed by a filter
+	originalSeverity string
+	// status and first seen date are only set when comparing with the state of a previous run
+	status    string
+	firstSeen time.Time
 }
 
 func transformFinding(aF *inspector.Finding) (out finding) {
+	out.Arn = aF.Arn
 	out.Service = aF.Service
 	out.ServiceAttributes = aF.ServiceAttributes
 	out.Severity = aF.Severity
@@ -0,0 +1,170 @@
+package air
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/inspector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterFindingTitle(t *testing.T) {
+	f := testFinding("1.4.2 Ensure bootloader password is set", "Medium", "i-0000000001", "web")
+	out, matched, _ := filterFinding(f, findingContext{}, filters{
+		{TitleMatch: "^5.3.1", Severity: "ignore"},
+		{TitleMatch: "^1.4.2", Severity: "low", Comment: "not viable in AWS"},
+	})
+	assert.Equal(t, 1, matched)
+	assert.Equal(t, "low", *out.Severity)
+	assert.Equal(t, "not viable in AWS", out.comment)
+	assert.Equal(t, "^1.4.2", out.matchedFilter.TitleMatch)
+	// original is unchanged
+	assert.Equal(t, "Medium", *f.Severity)
+}
+
+func TestFilterFindingNoCriteria(t *testing.T) {
+	f := testFinding("CVE-2019-0001", "High", "i-0000000001", "web")
+	out, matched, _ := filterFinding(f, findingContext{}, filters{{Severity: "ignore"}})
+	assert.Equal(t, -1, matched)
+	assert.Equal(t, "High", *out.Severity)
+	assert.Nil(t, out.matchedFilter)
+}
+
+func TestFilterFindingAllCriteria(t *testing.T) {
+	f := testFinding("1.4.2 Ensure bootloader password is set", "Medium", "i-0000000001", "bastion")
+	f.AssetAttributes.AutoScalingGroup = ptrToStr("dev-bastion-asg")
+	f.AssetAttributes.AmiId = ptrToStr("ami-0123456789")
+	f.AssetAttributes.Tags = append(f.AssetAttributes.Tags, &inspector.Tag{Key: ptrToStr("Environment"), Value: ptrToStr("dev")})
+	f.Attributes = []*inspector.Attribute{{Key: ptrToStr("CVE_ID"), Value: ptrToStr("CVE-2019-0001")}}
+	f.rulePackageName = "CIS Operating System Security Configuration Benchmarks-1.0"
+	ctx := findingContext{accountID: "012345678901", accountAlias: "acme-dev", region: "eu-west-1", templateName: "weekly"}
+
+	matching := filter{
+		TitleMatch:        "^1.4.2",
+		RulesPackageMatch: "^CIS",
+		RegionMatch:       "^eu-",
+		AccountMatch:      "^acme-dev$",
+		TemplateMatch:     "weekly",
+		ASGMatch:          "bastion",
+		AMIMatch:          "^ami-0123",
+		TagMatch:          []string{"^Environment$=^dev$", "Name=bastion"},
+		AttributeMatch:    []string{"CVE_ID=CVE-2019-0001"},
+		Severity:          "ignore",
+	}
+	assert.True(t, matching.matches(f, ctx))
+
+	// account can also be matched on id
+	byID := matching
+	byID.AccountMatch = "^012345678901$"
+	assert.True(t, byID.matches(f, ctx))
+
+	// all criteria must match
+	for _, nonMatching := range []filter{
+		{TitleMatch: "^1.4.2", AccountMatch: "prod"},
+		{TitleMatch: "^1.4.2", RegionMatch: "^us-"},
+		{TitleMatch: "^1.4.2", ASGMatch: "web"},
+		{TitleMatch: "^1.4.2", AMIMatch: "ami-999"},
+		{TitleMatch: "^1.4.2", TemplateMatch: "daily"},
+		{TitleMatch: "^1.4.2", RulesPackageMatch: "^Common"},
+		{TitleMatch: "^1.4.2", TagMatch: []string{"Environment=prod"}},
+		{TitleMatch: "^1.4.2", TagMatch: []string{"Owner=.*"}},
+		{TitleMatch: "^1.4.2", AttributeMatch: []string{"CVE_ID=CVE-2019-0002"}},
+	} {
+		assert.False(t, nonMatching.matches(f, ctx), nonMatching.criteria())
+	}
+}
+
+func TestFilterAccountsResults(t *testing.T) {
+	ar := testAccountsResults()
+	usage := ar.filter(filters{
+		{TitleMatch: "^CVE-2019-0001", AccountMatch: "nonprod", Severity: "low"},
+		{TitleMatch: "^CVE-2019-0002", Severity: "low"},
+		{TitleMatch: "^5.3.1", Severity: "ignore"},
+	})
+	assert.Len(t, usage, 3)
+	assert.Equal(t, map[string]int{"012345678901": 2}, usage[0].accounts)
+	assert.Equal(t, 2, usage[0].total)
+	assert.Equal(t, map[string]int{"987654321098": 1}, usage[1].accounts)
+	assert.Equal(t, 0, usage[2].total)
+	assert.Equal(t, filters{{TitleMatch: "^5.3.1", Severity: "ignore"}}, usage.unused())
+	assert.Empty(t, usage.expiredMatches())
+	assert.Equal(t, "low", *ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[0].Severity)
+	assert.Equal(t, "low", *ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[1].Severity)
+	assert.Equal(t, "Medium", *ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[2].Severity)
+	assert.Equal(t, "low", *ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings[0].Severity)
+}
+
+func TestFilterUsageExpired(t *testing.T) {
+	ar := testAccountsResults()
+	usage := ar.filter(filters{
+		{TitleMatch: "^CVE-2019-0002", Severity: "low", Expires: "2019-01-01"},
+		{TitleMatch: "^5.3.1", Severity: "ignore", Expires: "2019-01-01"},
+	})
+	// a filter that matches findings after expiring is not unused
+	assert.Equal(t, 0, usage[0].total)
+	assert.Equal(t, 1, usage[0].expired)
+	assert.Equal(t, filters{usage[1].filter}, usage.unused())
+	assert.Len(t, usage.expiredMatches(), 1)
+}
+
+func TestFilterCriteria(t *testing.T) {
+	f := filter{TitleMatch: "^1.4.2", ASGMatch: "bastion", TagMatch: []string{"Environment=dev"}}
+	assert.Equal(t, []string{"title-match: ^1.4.2", "asg-match: bastion", "tag-match: Environment=dev"}, f.criteria())
+}
+
+func TestFilterExpired(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, filter{}.expired(now))
+	assert.False(t, filter{Expires: "2019-06-01"}.expired(now))
+	assert.True(t, filter{Expires: "2019-05-31"}.expired(now))
+	assert.True(t, filter{Expires: "01/06/2019"}.expired(now))
+}
+
+func TestFilterFindingExpired(t *testing.T) {
+	f := testFinding("1.4.2 Ensure bootloader password is set", "Medium", "i-0000000001", "web")
+	out, matched, expired := filterFinding(f, findingContext{}, filters{
+		{TitleMatch: "^1.4.2", Severity: "ignore", Expires: "2019-01-01", Owner: "alice", Ticket: "SEC-123"},
+	})
+	assert.Equal(t, -1, matched)
+	assert.Equal(t, 0, expired)
+	assert.Equal(t, "Medium", *out.Severity)
+	assert.Nil(t, out.matchedFilter)
+	assert.Equal(t, "2019-01-01", out.expiredFilter.Expires)
+	assert.Equal(t, "suppression expired 2019-01-01 (owner: alice, ticket: SEC-123)", out.comment)
+
+	// a subsequent filter that hasn't expired still applies
+	out, matched, expired = filterFinding(f, findingContext{}, filters{
+		{TitleMatch: "^1.4.2", Severity: "ignore", Expires: "2019-01-01"},
+		{TitleMatch: "bootloader", Severity: "low", Expires: "2099-01-01", Comment: "extended"},
+	})
+	assert.Equal(t, 1, matched)
+	assert.Equal(t, 0, expired)
+	assert.Equal(t, "low", *out.Severity)
+	assert.Equal(t, "extended", out.comment)
+	assert.Nil(t, out.expiredFilter)
+}
+
+func TestExpiringFilters(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiring := expiringFilters(filters{
+		{TitleMatch: "a"},
+		{TitleMatch: "b", Expires: "2019-05-01"},
+		{TitleMatch: "c", Expires: "2019-06-10"},
+		{TitleMatch: "d", Expires: "2019-07-01"},
+		{TitleMatch: "e", Expires: "invalid"},
+	}, now, 14)
+	assert.Len(t, expiring, 3)
+	assert.Equal(t, "b", expiring[0].TitleMatch)
+	assert.Equal(t, "c", expiring[1].TitleMatch)
+	assert.Equal(t, "e", expiring[2].TitleMatch)
+}
+
+func TestFilterInvalidPattern(t *testing.T) {
+	f := filter{TitleMatch: "^1.4.2 (Ensure", Severity: "ignore"}
+	assert.Len(t, f.invalidPatterns(), 1)
+	assert.False(t, f.matches(testFinding("1.4.2 (Ensure bootloader password is set", "Medium", "i-0000000001", "web"), findingContext{}))
+
+	f = filter{TitleMatch: "^1.4.2", TagMatch: []string{"Name=[web"}, Severity: "ignore"}
+	assert.Len(t, f.invalidPatterns(), 1)
+	assert.False(t, f.matches(testFinding("1.4.2 Ensure bootloader password is set", "Medium", "i-0000000001", "web"), findingContext{}))
+}
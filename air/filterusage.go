@@ -0,0 +1,50 @@
+package air
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const filterUsageSheetName = "Filter Usage"
+
+// addFilterUsageSheet lists each filter with the number of findings it was applied to per account
+// filters that did not match any findings are highlighted as candidates for removal
+// findings matched by filters that have expired are counted separately as they are no longer suppressed
+func addFilterUsageSheet(xlsx *excelize.File, styles spreadsheetStyles, usage filterUsages, accountsResults accountsResults) {
+	sheetName := filterUsageSheetName
+	_ = xlsx.NewSheet(sheetName)
+	header := []interface{}{"FILTER", "SEVERITY", "COMMENT", "TOTAL", "EXPIRED MATCHES"}
+	for _, ar := range accountsResults {
+		name := ar.accountAlias
+		if name == "" {
+			name = ar.accountID
+		}
+		header = append(header, name)
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 70)
+	_ = xlsx.SetColWidth(sheetName, "B", "B", 14)
+	_ = xlsx.SetColWidth(sheetName, "C", "C", 50)
+	_ = xlsx.SetColWidth(sheetName, "D", lastCol, 18)
+	_ = xlsx.SetSheetRow(sheetName, "A1", &header)
+	_ = xlsx.SetCellStyle(sheetName, "A1", fmt.Sprintf("%s1", lastCol), styles.header)
+
+	for i, fu := range usage {
+		row := i + 2
+		values := []interface{}{strings.Join(fu.filter.criteria(), "\r\n"), strings.ToUpper(fu.filter.Severity), fu.filter.Comment, fu.total, fu.expired}
+		for _, ar := range accountsResults {
+			values = append(values, fu.accounts[ar.accountID])
+		}
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &values)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("%s%d", lastCol, row), styles.defaultCentered)
+		if fu.total == 0 && fu.expired == 0 {
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("D%d", row), styles.high)
+		}
+		if fu.expired > 0 {
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("E%d", row), styles.high)
+		}
+	}
+	_ = xlsx.AutoFilter(sheetName, "A1", fmt.Sprintf("%s%d", lastCol, len(usage)+1), "")
+}
@@ -0,0 +1,241 @@
+package air
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type htmlReport struct {
+	GeneratedAt string
+	Severities  []string
+	Accounts    []htmlAccount
+	// whether to show the columns only reported by Inspector v2
+	Inspector2 bool
+}
+
+type htmlAccount struct {
+	ID     string
+	Alias  string
+	Counts []int
+	Total  int
+	Rows   []htmlRow
+}
+
+type htmlRow struct {
+	Severity       string
+	Region         string
+	Template       string
+	Date           string
+	InstanceID     string
+	InstanceName   string
+	AMIID          string
+	ASG            string
+	RulesPackage   string
+	Title          string
+	Description    string
+	Recommendation string
+	Comment        string
+	// only reported by Inspector v2
+	ResourceType    string
+	VulnerabilityID string
+	CVSS            string
+	FixAvailable    string
+	Packages        []string
+}
+
+func newHTMLReport(accountsResults accountsResults, timeStamp time.Time) (report htmlReport) {
+	report.GeneratedAt = timeStamp.UTC().Format(time.ANSIC) + " UTC"
+	report.Severities = reportSeverities
+	for _, accountResults := range accountsResults {
+		data := generateAccountRegionXLSXData(accountResults)
+		if len(data) == 0 {
+			continue
+		}
+		account := htmlAccount{
+			ID:     accountResults.accountID,
+			Alias:  accountResults.accountAlias,
+			Counts: make([]int, len(reportSeverities)),
+			Total:  len(data),
+		}
+		for _, dr := range data {
+			for i, severity := range reportSeverities {
+				if dr.severity == severity {
+					account.Counts[i]++
+				}
+			}
+			account.Rows = append(account.Rows, htmlRow{
+				Severity:        dr.severity,
+				Region:          dr.region,
+				Template:        dr.templateName,
+				Date:            dr.createdAt.Format(time.ANSIC),
+				InstanceID:      dr.instanceID,
+				InstanceName:    dr.instanceName,
+				AMIID:           dr.amiID,
+				ASG:             dr.asgName,
+				RulesPackage:    dr.packageName,
+				Title:           dr.findingTitle,
+				Description:     dr.description,
+				Recommendation:  dr.recommendation,
+				Comment:         dr.comment,
+				ResourceType:    dr.resourceType,
+				VulnerabilityID: dr.vulnerabilityID,
+				CVSS:            dr.cvssScore,
+				FixAvailable:    dr.fixAvailable,
+				Packages:        dr.packages,
+			})
+			if dr.resourceType != "" {
+				report.Inspector2 = true
+			}
+		}
+		report.Accounts = append(report.Accounts, account)
+	}
+	return report
+}
+
+func renderHTMLReport(report htmlReport) ([]byte, error) {
+	t, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, report); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func generateHTML(accountsResults accountsResults, outputDir string, timeStamp time.Time) (string, error) {
+	content, err := renderHTMLReport(newHTMLReport(accountsResults, timeStamp))
+	if err != nil {
+		return "", err
+	}
+	path := getReportPath(outputDir, timeStamp, FormatHTML)
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		return "", errors.WithStack(err)
+	}
+	absPath, _ := filepath.Abs(path)
+	fmt.Println("report written to:", absPath)
+	return absPath, err
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>AWS Inspector Report</title>
+<style>
+body { font-family: Calibri, Arial, sans-serif; font-size: 14px; color: #000000; margin: 20px; }
+h1 { color: #000066; }
+h2 { color: #000066; margin-top: 40px; }
+table { border-collapse: collapse; margin-bottom: 10px; }
+th { background-color: #000066; color: #f2f2f2; padding: 6px 10px; text-align: center; }
+table.findings th { cursor: pointer; user-select: none; }
+td { border: 1px solid #d9d9d9; padding: 4px 8px; vertical-align: top; }
+td.count { text-align: center; }
+td.text { white-space: pre-wrap; max-width: 600px; }
+.CRITICAL { color: #800000; font-weight: bold; }
+.HIGH { color: #cc0000; font-weight: bold; }
+.MEDIUM { color: #cc6600; font-weight: bold; }
+.LOW { color: #003399; font-weight: bold; }
+.INFORMATIONAL, .IGNORE { color: #000000; font-weight: bold; }
+input.filter { margin-bottom: 8px; padding: 4px; width: 300px; }
+</style>
+</head>
+<body>
+<h1>AWS Inspector Report</h1>
+<p>Generated: {{.GeneratedAt}}</p>
+<table>
+<tr><th>ACCOUNT</th>{{range .Severities}}<th>{{.}}</th>{{end}}<th>TOTAL</th></tr>
+{{- range .Accounts}}
+<tr><td><a href="#account-{{.ID}}">{{.Alias}} ({{.ID}})</a></td>{{range .Counts}}<td class="count">{{.}}</td>{{end}}<td class="count">{{.Total}}</td></tr>
+{{- end}}
+</table>
+{{- range .Accounts}}
+<h2 id="account-{{.ID}}">{{.Alias}} ({{.ID}})</h2>
+<input class="filter" type="text" placeholder="filter..." onkeyup="filterTable(this, 'table-{{.ID}}')">
+<table class="findings" id="table-{{.ID}}">
+<thead>
+<tr><th>SEVERITY</th><th>REGION</th><th>TEMPLATE</th><th>DATE</th><th>INSTANCE ID</th><th>INSTANCE NAME</th><th>ASG</th><th>RULES PACKAGE</th><th>TITLE</th><th>DESCRIPTION</th><th>RECOMMENDATION</th>
+{{- if $.Inspector2}}<th>RESOURCE TYPE</th><th>VULNERABILITY ID</th><th>CVSS</th><th>FIX AVAILABLE</th><th>PACKAGES</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>
+<td class="{{.Severity}}"{{if .Comment}} title="{{.Comment}}"{{end}}>{{.Severity}}</td>
+<td>{{.Region}}</td>
+<td>{{.Template}}</td>
+<td>{{.Date}}</td>
+<td{{if .AMIID}} title="AMI: {{.AMIID}}"{{end}}>{{.InstanceID}}</td>
+<td>{{.InstanceName}}</td>
+<td>{{.ASG}}</td>
+<td>{{.RulesPackage}}</td>
+<td class="text">{{.Title}}</td>
+<td class="text"><details><summary>show</summary>{{.Description}}</details></td>
+<td class="text"><details><summary>show</summary>{{.Recommendation}}</details></td>
+{{- if $.Inspector2}}
+<td>{{.ResourceType}}</td>
+<td>{{.VulnerabilityID}}</td>
+<td>{{.CVSS}}</td>
+<td>{{.FixAvailable}}</td>
+<td class="text">{{range $i, $p := .Packages}}{{if $i}}<br>{{end}}{{$p}}{{end}}</td>
+{{- end}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+<script>
+var severityOrder = {"CRITICAL": 5, "HIGH": 4, "MEDIUM": 3, "LOW": 2, "INFORMATIONAL": 1, "IGNORE": 0};
+function filterTable(input, tableID) {
+  var term = input.value.toLowerCase();
+  var rows = document.getElementById(tableID).tBodies[0].rows;
+  for (var i = 0; i < rows.length; i++) {
+    rows[i].style.display = rows[i].textContent.toLowerCase().indexOf(term) > -1 ? "" : "none";
+  }
+}
+function sortTable(table, column, ascending) {
+  var tbody = table.tBodies[0];
+  var rows = Array.prototype.slice.call(tbody.rows);
+  rows.sort(function (a, b) {
+    var x = a.cells[column].textContent.trim();
+    var y = b.cells[column].textContent.trim();
+    if (column === 0) {
+      x = severityOrder[x];
+      y = severityOrder[y];
+    } else if (column === 3) {
+      x = Date.parse(x);
+      y = Date.parse(y);
+    }
+    if (x < y) { return ascending ? -1 : 1; }
+    if (x > y) { return ascending ? 1 : -1; }
+    return 0;
+  });
+  for (var i = 0; i < rows.length; i++) {
+    tbody.appendChild(rows[i]);
+  }
+}
+var tables = document.querySelectorAll("table.findings");
+for (var t = 0; t < tables.length; t++) {
+  (function (table) {
+    var headers = table.tHead.rows[0].cells;
+    for (var c = 0; c < headers.length; c++) {
+      (function (column, header) {
+        header.addEventListener("click", function () {
+          var ascending = header.getAttribute("data-order") !== "asc";
+          header.setAttribute("data-order", ascending ? "asc" : "desc");
+          sortTable(table, column, ascending);
+        });
+      })(c, headers[c]);
+    }
+  })(tables[t]);
+}
+</script>
+</body>
+</html>
+`
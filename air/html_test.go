@@ -0,0 +1,30 @@
+package air
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTMLReport(t *testing.T) {
+	report := newHTMLReport(testAccountsResults(), time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "Sat Jun  1 00:00:00 2019 UTC", report.GeneratedAt)
+	assert.Len(t, report.Accounts, 2)
+	assert.Equal(t, []int{0, 2, 1, 0, 0, 0}, report.Accounts[0].Counts)
+	assert.Equal(t, 3, report.Accounts[0].Total)
+	assert.Equal(t, []int{0, 0, 0, 1, 0, 0}, report.Accounts[1].Counts)
+	assert.Len(t, report.Accounts[1].Rows, 1)
+}
+
+func TestRenderHTMLReport(t *testing.T) {
+	ar := testAccountsResults()
+	ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings[0].Title = ptrToStr("<script>alert(1)</script>")
+	content, err := renderHTMLReport(newHTMLReport(ar, time.Now()))
+	assert.NoError(t, err)
+	output := string(content)
+	assert.True(t, strings.Contains(output, `<h2 id="account-987654321098">acme-prod (987654321098)</h2>`))
+	assert.True(t, strings.Contains(output, "&lt;script&gt;alert(1)&lt;/script&gt;"))
+	assert.False(t, strings.Contains(output, "<script>alert(1)</script>"))
+}
@@ -0,0 +1,338 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/inspector"
+	"github.com/aws/aws-sdk-go/service/inspector2"
+	"github.com/aws/aws-sdk-go/service/inspector2/inspector2iface"
+	"github.com/pkg/errors"
+)
+
+const (
+	// InspectorClassic retrieves findings from the latest assessment runs of Inspector Classic
+	InspectorClassic = "classic"
+	// InspectorV2 retrieves the active findings of Inspector (v2) for EC2 instances, ECR images and Lambda functions
+	InspectorV2 = "v2"
+	// InspectorAll retrieves findings from both
+	InspectorAll = "all"
+
+	// inspector2TemplateName is used in place of an assessment template name for Inspector v2 findings
+	inspector2TemplateName = "Inspector v2"
+)
+
+var supportedInspectorVersions = []string{InspectorClassic, InspectorV2, InspectorAll}
+
+// attributes added to Inspector v2 findings so the details are available to filters and kept in JSON reports
+const (
+	attributeFindingType      = "FINDING_TYPE"
+	attributeResourceType     = "RESOURCE_TYPE"
+	attributeVulnerabilityID  = "CVE_ID"
+	attributeCVSSScore        = "CVSS_SCORE"
+	attributeFixAvailable     = "FIX_AVAILABLE"
+	attributeExploitAvailable = "EXPLOIT_AVAILABLE"
+	attributePackage          = "PACKAGE"
+	attributeOpenPorts        = "OPEN_PORTS"
+	attributeNetworkPath      = "NETWORK_PATH"
+)
+
+// inspector2FindingTypes are the names shown in place of a rules package for each type of finding
+var inspector2FindingTypes = map[string]string{
+	inspector2.FindingTypePackageVulnerability: "Package Vulnerability",
+	inspector2.FindingTypeNetworkReachability:  "Network Reachability",
+	inspector2.FindingTypeCodeVulnerability:    "Code Vulnerability",
+}
+
+// inspectorVersion returns the normalised version, defaulting to classic if not set
+func inspectorVersion(version string) (string, error) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version == "" {
+		return InspectorClassic, nil
+	}
+	if !stringInSlice(version, supportedInspectorVersions) {
+		return "", fmt.Errorf("inspector version '%s' not supported, valid versions are: %s", version, strings.Join(supportedInspectorVersions, ", "))
+	}
+	return version, nil
+}
+
+// inspectorRegions returns the regions to retrieve findings from for each of the versions of Inspector specified
+// along with a warning for each requested region a version isn't available in
+func (opts collectionOptions) inspectorRegions(version string) (classic, v2, warnings []string) {
+	var unavailable []string
+	if version == InspectorClassic || version == InspectorAll {
+		classic, unavailable = opts.selectRegions(getAllInspectorRegions(opts.partition))
+		for _, r := range unavailable {
+			warnings = append(warnings, fmt.Sprintf("Inspector Classic is not available in requested region: %s", r))
+		}
+	}
+	if version == InspectorV2 || version == InspectorAll {
+		v2, unavailable = opts.selectRegions(getAllInspector2Regions(opts.partition))
+		for _, r := range unavailable {
+			warnings = append(warnings, fmt.Sprintf("Inspector v2 is not available in requested region: %s", r))
+		}
+	}
+	return
+}
+
+// processRegions retrieves the findings for an account from the regions of each version of Inspector
+// results for the same region are combined, with the Inspector v2 findings following those of any assessment templates
+// regions that fail are omitted from the results and an error returned for each
+func processRegions(creds *credentials.Credentials, accountID string, classicRegions, v2Regions []string, opts collectionOptions) (results []regionResult, errs []annotatedError) {
+	if len(classicRegions) > 0 {
+		results, errs = processAllRegions(creds, classicRegions, opts.maxReportAge, opts.regionConcurrency)
+	}
+	if len(v2Regions) > 0 {
+		v2Results, v2Errs := processAllInspector2Regions(creds, accountID, v2Regions, opts.regionConcurrency)
+		results = mergeRegionResults(results, v2Results)
+		errs = append(errs, v2Errs...)
+	}
+	return
+}
+
+func mergeRegionResults(results, additional []regionResult) []regionResult {
+	for _, ar := range additional {
+		merged := false
+		for i := range results {
+			if results[i].region == ar.region {
+				results[i].regionTemplateResults = append(results[i].regionTemplateResults, ar.regionTemplateResults...)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			results = append(results, ar)
+		}
+	}
+	return results
+}
+
+func processAllInspector2Regions(creds *credentials.Credentials, accountID string, regions []string, concurrency int) ([]regionResult, []annotatedError) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	perRegionResults := make([]regionResult, len(regions))
+	perRegionErrors := make([]error, len(regions))
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			sess, err := session.NewSession(&aws.Config{Credentials: creds, Region: &region})
+			if err != nil {
+				perRegionErrors[i] = errors.WithStack(err)
+				return
+			}
+			rtr, err := getInspector2Results(inspector2.New(sess), accountID)
+			if err != nil {
+				perRegionErrors[i] = errors.WithStack(err)
+				return
+			}
+			perRegionResults[i] = regionResult{region: region}
+			if len(rtr.runs[0].findings) > 0 {
+				perRegionResults[i].regionTemplateResults = []regionTemplateResult{rtr}
+			}
+		}(i, region)
+	}
+	wg.Wait()
+	return collectRegionResults(regions, perRegionResults, perRegionErrors, "Inspector v2")
+}
+
+// inspector2FilterCriteria limits the findings to the active findings of the account
+// without the account filter, a delegated administrator would also get the findings of every member account
+func inspector2FilterCriteria(accountID string) *inspector2.FilterCriteria {
+	return &inspector2.FilterCriteria{
+		AwsAccountId: []*inspector2.StringFilter{{
+			Comparison: ptrToStr(inspector2.StringComparisonEquals),
+			Value:      ptrToStr(accountID),
+		}},
+		FindingStatus: []*inspector2.StringFilter{{
+			Comparison: ptrToStr(inspector2.StringComparisonEquals),
+			Value:      ptrToStr(inspector2.FindingStatusActive),
+		}},
+	}
+}
+
+// getInspector2Results pages through the active findings of the account in a region
+// Inspector v2 has no assessment templates or runs, so the findings are returned as a single run of a pseudo template
+func getInspector2Results(svc inspector2iface.Inspector2API, accountID string) (result regionTemplateResult, err error) {
+	result.templateName = inspector2TemplateName
+	var r run
+	var nextToken *string
+	for {
+		lfi := &inspector2.ListFindingsInput{
+			FilterCriteria: inspector2FilterCriteria(accountID),
+			MaxResults:     ptrToInt64(100),
+			NextToken:      nextToken,
+		}
+		var lfo *inspector2.ListFindingsOutput
+		lfo, err = svc.ListFindings(lfi)
+		if err != nil {
+			return result, err
+		}
+		for _, f := range lfo.Findings {
+			r.findings = append(r.findings, transformInspector2Finding(f))
+		}
+		if lfo.NextToken == nil || *lfo.NextToken == "" {
+			break
+		}
+		nextToken = lfo.NextToken
+	}
+	result.runs = []run{r}
+	return result, nil
+}
+
+// transformInspector2Finding maps an Inspector v2 finding onto the fields of a classic finding used by the reports
+// details without an equivalent are added as attributes
+func transformInspector2Finding(f *inspector2.Finding) (out finding) {
+	out.Arn = f.FindingArn
+	out.Service = ptrToStr("Inspector2")
+	out.Severity = ptrToStr(inspector2Severity(derefStr(f.Severity)))
+	out.NumericSeverity = f.InspectorScore
+	out.CreatedAt = f.FirstObservedAt
+	out.UpdatedAt = f.UpdatedAt
+	if out.UpdatedAt == nil {
+		out.UpdatedAt = f.LastObservedAt
+	}
+	out.Title = f.Title
+	out.Description = f.Description
+	out.Recommendation = ptrToStr("-")
+	if f.Remediation != nil && f.Remediation.Recommendation != nil && f.Remediation.Recommendation.Text != nil {
+		out.Recommendation = f.Remediation.Recommendation.Text
+	}
+	out.ServiceAttributes = &inspector.ServiceAttributes{RulesPackageArn: ptrToStr("")}
+	out.rulePackageName = inspector2FindingTypes[derefStr(f.Type)]
+	if out.rulePackageName == "" {
+		out.rulePackageName = derefStr(f.Type)
+	}
+	out.AssetAttributes = &inspector.AssetAttributes{AgentId: ptrToStr("-")}
+
+	addAttribute := func(key, value string) {
+		if value != "" {
+			out.Attributes = append(out.Attributes, &inspector.Attribute{Key: ptrToStr(key), Value: ptrToStr(value)})
+		}
+	}
+	addAttribute(attributeFindingType, derefStr(f.Type))
+	addAttribute(attributeFixAvailable, derefStr(f.FixAvailable))
+	addAttribute(attributeExploitAvailable, derefStr(f.ExploitAvailable))
+
+	if len(f.Resources) > 0 {
+		resource := f.Resources[0]
+		addAttribute(attributeResourceType, derefStr(resource.Type))
+		out.AssetAttributes = inspector2AssetAttributes(resource)
+	}
+	if pvd := f.PackageVulnerabilityDetails; pvd != nil {
+		addAttribute(attributeVulnerabilityID, derefStr(pvd.VulnerabilityId))
+		addAttribute(attributeCVSSScore, cvssScore(pvd.Cvss))
+		for _, vp := range pvd.VulnerablePackages {
+			pkg := strings.TrimSuffix(derefStr(vp.Name)+" "+derefStr(vp.Version), " ")
+			if vp.FixedInVersion != nil && *vp.FixedInVersion != "NotAvailable" {
+				pkg += fmt.Sprintf(" (fixed in %s)", *vp.FixedInVersion)
+			}
+			addAttribute(attributePackage, pkg)
+		}
+		if out.Title == nil {
+			out.Title = pvd.VulnerabilityId
+		}
+	}
+	if nrd := f.NetworkReachabilityDetails; nrd != nil {
+		if nrd.OpenPortRange != nil {
+			addAttribute(attributeOpenPorts, fmt.Sprintf("%s %d-%d", derefStr(nrd.Protocol),
+				aws.Int64Value(nrd.OpenPortRange.Begin), aws.Int64Value(nrd.OpenPortRange.End)))
+		}
+		if nrd.NetworkPath != nil {
+			var steps []string
+			for _, s := range nrd.NetworkPath.Steps {
+				steps = append(steps, derefStr(s.ComponentId))
+			}
+			addAttribute(attributeNetworkPath, strings.Join(steps, " > "))
+		}
+	}
+	if out.Title == nil {
+		out.Title = ptrToStr("-")
+	}
+	if out.Description == nil {
+		out.Description = ptrToStr("-")
+	}
+	return out
+}
+
+// inspector2Severity returns the severity in the same form as Inspector Classic
+// untriaged findings, which have no severity assigned by the vendor, are treated as informational
+func inspector2Severity(severity string) string {
+	switch severity {
+	case inspector2.SeverityUntriaged, "":
+		return "Informational"
+	}
+	return strings.Title(strings.ToLower(severity))
+}
+
+// inspector2AssetAttributes returns the details of the affected resource
+// resources without a Name tag are given one from the function or repository name so they can be identified in reports
+func inspector2AssetAttributes(resource *inspector2.Resource) *inspector.AssetAttributes {
+	aa := &inspector.AssetAttributes{AgentId: ptrToStr(derefStr(resource.Id))}
+	keys := make([]string, 0, len(resource.Tags))
+	for k := range resource.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		aa.Tags = append(aa.Tags, &inspector.Tag{Key: ptrToStr(k), Value: ptrToStr(derefStr(resource.Tags[k]))})
+	}
+	var name string
+	if resource.Details != nil {
+		switch {
+		case resource.Details.AwsEc2Instance != nil:
+			aa.AmiId = resource.Details.AwsEc2Instance.ImageId
+			aa.Ipv4Addresses = resource.Details.AwsEc2Instance.IpV4Addresses
+		case resource.Details.AwsEcrContainerImage != nil:
+			image := resource.Details.AwsEcrContainerImage
+			name = derefStr(image.RepositoryName)
+			if len(image.ImageTags) > 0 {
+				name += ":" + derefStr(image.ImageTags[0])
+			}
+		case resource.Details.AwsLambdaFunction != nil:
+			name = derefStr(resource.Details.AwsLambdaFunction.FunctionName)
+		}
+	}
+	if _, ok := resource.Tags["Name"]; !ok && name != "" {
+		aa.Tags = append(aa.Tags, &inspector.Tag{Key: ptrToStr("Name"), Value: ptrToStr(name)})
+	}
+	return aa
+}
+
+// cvssScore returns the base score of the most recent version of CVSS scored
+func cvssScore(scores []*inspector2.CvssScore) string {
+	var latest *inspector2.CvssScore
+	for _, s := range scores {
+		if s.BaseScore == nil {
+			continue
+		}
+		if latest == nil || derefStr(s.Version) > derefStr(latest.Version) {
+			latest = s
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", *latest.BaseScore)
+}
+
+// attributeValues returns the values of the finding's attributes with the key specified
+func attributeValues(f finding, key string) (values []string) {
+	for _, a := range f.Attributes {
+		if derefStr(a.Key) == key && a.Value != nil {
+			values = append(values, *a.Value)
+		}
+	}
+	return values
+}
+
+func getAllInspector2Regions(partition string) []string {
+	return getServiceRegions(partition, "inspector2")
+}
@@ -0,0 +1,138 @@
+package air
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/inspector2"
+	a "github.com/jonhadfield/aws-inspector-reporter/air/airtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectorVersion(t *testing.T) {
+	version, err := inspectorVersion("")
+	assert.NoError(t, err)
+	assert.Equal(t, InspectorClassic, version)
+	version, err = inspectorVersion(" V2 ")
+	assert.NoError(t, err)
+	assert.Equal(t, InspectorV2, version)
+	_, err = inspectorVersion("v3")
+	assert.EqualError(t, err, "inspector version 'v3' not supported, valid versions are: classic, v2, all")
+}
+
+func TestGetInspector2Results(t *testing.T) {
+	result, err := getInspector2Results(&a.MockInspector2Client{}, "012345678901")
+	assert.NoError(t, err)
+	assert.Equal(t, inspector2TemplateName, result.templateName)
+	assert.Len(t, result.runs, 1)
+	fs := result.runs[0].findings
+	assert.Len(t, fs, 3)
+
+	// package vulnerability on an EC2 instance
+	assert.Equal(t, "Critical", *fs[0].Severity)
+	assert.Equal(t, "Package Vulnerability", fs[0].rulePackageName)
+	assert.Equal(t, "i-0123456789abcdef0", *fs[0].AssetAttributes.AgentId)
+	assert.Equal(t, "ami-0123456789abcdef0", *fs[0].AssetAttributes.AmiId)
+	assert.Equal(t, "web-server", getInstanceName(fs[0]))
+	assert.Equal(t, "Upgrade openssl.", *fs[0].Recommendation)
+	assert.Equal(t, []string{"CVE-2019-0001"}, attributeValues(fs[0], attributeVulnerabilityID))
+	assert.Equal(t, []string{"9.8"}, attributeValues(fs[0], attributeCVSSScore))
+	assert.Equal(t, []string{"YES"}, attributeValues(fs[0], attributeFixAvailable))
+	assert.Equal(t, []string{"openssl 1.0.2k (fixed in 1.0.2l)"}, attributeValues(fs[0], attributePackage))
+	assert.Equal(t, []string{"AWS_EC2_INSTANCE"}, attributeValues(fs[0], attributeResourceType))
+
+	// network reachability
+	assert.Equal(t, "Medium", *fs[1].Severity)
+	assert.Equal(t, "Network Reachability", fs[1].rulePackageName)
+	assert.Equal(t, "-", *fs[1].Recommendation)
+	assert.Equal(t, []string{"TCP 22-22"}, attributeValues(fs[1], attributeOpenPorts))
+	assert.Equal(t, []string{"igw-01234567 > sg-01234567"}, attributeValues(fs[1], attributeNetworkPath))
+
+	// untriaged package vulnerability in an ECR image without a title
+	assert.Equal(t, "Informational", *fs[2].Severity)
+	assert.Equal(t, "GHSA-0000-0000-0000", *fs[2].Title)
+	assert.Equal(t, "app:v1.2.0", getInstanceName(fs[2]))
+	assert.Equal(t, []string{"requests 2.19.0"}, attributeValues(fs[2], attributePackage))
+	assert.Empty(t, attributeValues(fs[2], attributeCVSSScore))
+}
+
+func TestGetInspector2ResultsFilterCriteria(t *testing.T) {
+	mock := &a.MockInspector2Client{}
+	_, err := getInspector2Results(mock, "012345678901")
+	assert.NoError(t, err)
+	assert.Len(t, mock.Inputs, 2)
+	for _, input := range mock.Inputs {
+		// only the findings of the target account, not those of member accounts when it is the delegated administrator
+		assert.Len(t, input.FilterCriteria.AwsAccountId, 1)
+		assert.Equal(t, inspector2.StringComparisonEquals, *input.FilterCriteria.AwsAccountId[0].Comparison)
+		assert.Equal(t, "012345678901", *input.FilterCriteria.AwsAccountId[0].Value)
+		assert.Len(t, input.FilterCriteria.FindingStatus, 1)
+		assert.Equal(t, inspector2.FindingStatusActive, *input.FilterCriteria.FindingStatus[0].Value)
+	}
+}
+
+func TestInspector2SpreadsheetData(t *testing.T) {
+	result, err := getInspector2Results(&a.MockInspector2Client{}, "012345678901")
+	assert.NoError(t, err)
+	ar := accountResults{accountID: "012345678901", regionResults: []regionResult{{region: "eu-west-2", regionTemplateResults: []regionTemplateResult{result}}}}
+	data := generateAccountRegionXLSXData(ar)
+	assert.Len(t, data, 3)
+	assert.Equal(t, "CRITICAL", data[0].severity)
+	assert.Equal(t, "Inspector v2", data[0].templateName)
+	assert.Equal(t, "AWS_EC2_INSTANCE", data[0].resourceType)
+	assert.Equal(t, "CVE-2019-0001", data[0].vulnerabilityID)
+	assert.Equal(t, "9.8", data[0].cvssScore)
+	assert.True(t, hasInspector2Data([]accountSpreadsheetData{{rows: data}}))
+}
+
+func TestMergeRegionResults(t *testing.T) {
+	classic := []regionResult{
+		{region: "eu-west-1", regionTemplateResults: []regionTemplateResult{{templateName: "Template"}}},
+		{region: "eu-west-2"},
+	}
+	v2 := []regionResult{
+		{region: "eu-west-2", regionTemplateResults: []regionTemplateResult{{templateName: inspector2TemplateName}}},
+		{region: "eu-west-3", regionTemplateResults: []regionTemplateResult{{templateName: inspector2TemplateName}}},
+	}
+	merged := mergeRegionResults(classic, v2)
+	assert.Len(t, merged, 3)
+	assert.Len(t, merged[0].regionTemplateResults, 1)
+	assert.Equal(t, inspector2TemplateName, merged[1].regionTemplateResults[0].templateName)
+	assert.Equal(t, "eu-west-3", merged[2].region)
+}
+
+func TestInspector2CSVAndHTML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	result, err := getInspector2Results(&a.MockInspector2Client{}, "012345678901")
+	assert.NoError(t, err)
+	ar := accountsResults{{accountID: "012345678901", regionResults: []regionResult{{region: "eu-west-2", regionTemplateResults: []regionTemplateResult{result}}}}}
+
+	path, err := generateCSV(ar, dir, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, []string{"AWS_EC2_INSTANCE", "CVE-2019-0001", "9.8", "YES", "openssl 1.0.2k (fixed in 1.0.2l)"}, records[1][len(csvHeader)-5:])
+
+	report := newHTMLReport(ar, time.Now())
+	assert.True(t, report.Inspector2)
+	content, err := renderHTMLReport(report)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(content), "<th>VULNERABILITY ID</th>"))
+	assert.True(t, strings.Contains(string(content), "<td>CVE-2019-0001</td>"))
+
+	// the columns are only shown in the HTML report when there are Inspector v2 findings
+	content, err = renderHTMLReport(newHTMLReport(testAccountsResults(), time.Now()))
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(content), "VULNERABILITY ID"))
+}
@@ -0,0 +1,119 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const instancesSheetName = "Instances"
+
+// instanceSummary is the number of findings, per severity, for a single instance
+type instanceSummary struct {
+	account       string
+	instanceID    string
+	instanceName  string
+	amiID         string
+	asgName       string
+	hostname      string
+	ipAddresses   []string
+	counts        []int
+	worstSeverity string
+	worstFinding  string
+}
+
+// riskier returns true if instance a should be remediated before instance b
+// comparing the number of findings of each severity, starting with the most severe
+func riskier(a, b instanceSummary) bool {
+	for i := range reportSeverities {
+		if reportSeverities[i] == "IGNORE" {
+			continue
+		}
+		if a.counts[i] != b.counts[i] {
+			return a.counts[i] > b.counts[i]
+		}
+	}
+	return a.instanceID < b.instanceID
+}
+
+func summariseInstances(data []accountSpreadsheetData) (instances []instanceSummary) {
+	lookup := make(map[string]int)
+	for _, accountData := range data {
+		for _, dr := range accountData.rows {
+			key := accountData.accountID + "|" + dr.instanceID
+			i, ok := lookup[key]
+			if !ok {
+				instances = append(instances, instanceSummary{
+					account:      accountData.sheetName,
+					instanceID:   dr.instanceID,
+					instanceName: dr.instanceName,
+					amiID:        dr.amiID,
+					asgName:      dr.asgName,
+					hostname:     dr.hostname,
+					ipAddresses:  dr.ipAddresses,
+					counts:       make([]int, len(reportSeverities)),
+				})
+				i = len(instances) - 1
+				lookup[key] = i
+			}
+			inst := &instances[i]
+			for si, severity := range reportSeverities {
+				if dr.severity == severity {
+					inst.counts[si]++
+				}
+			}
+			if severityRank[dr.severity] > severityRank[inst.worstSeverity] {
+				inst.worstSeverity = dr.severity
+				inst.worstFinding = dr.findingTitle
+			}
+		}
+	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		return riskier(instances[i], instances[j])
+	})
+	return instances
+}
+
+func addInstancesSheet(xlsx *excelize.File, styles spreadsheetStyles, instances []instanceSummary) {
+	sheetName := instancesSheetName
+	_ = xlsx.NewSheet(sheetName)
+	header := []interface{}{"ACCOUNT", "INSTANCE ID", "INSTANCE NAME"}
+	for _, severity := range reportSeverities {
+		header = append(header, severity)
+	}
+	header = append(header, "AMI", "ASG", "HOSTNAME", "IP ADDRESSES", "WORST FINDING")
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	_ = xlsx.SetSheetRow(sheetName, "A1", &header)
+	_ = xlsx.SetCellStyle(sheetName, "A1", lastCol+"1", styles.header)
+	firstCountCol, _ := excelize.ColumnNumberToName(4)
+	lastCountCol, _ := excelize.ColumnNumberToName(3 + len(reportSeverities))
+	_ = xlsx.SetColWidth(sheetName, "A", "C", 24)
+	_ = xlsx.SetColWidth(sheetName, firstCountCol, lastCountCol, 18)
+	_ = xlsx.SetColWidth(sheetName, lastCol, lastCol, 60)
+	for col := 4 + len(reportSeverities); col < len(header); col++ {
+		colName, _ := excelize.ColumnNumberToName(col)
+		_ = xlsx.SetColWidth(sheetName, colName, colName, 24)
+	}
+
+	row := 1
+	for _, inst := range instances {
+		row++
+		values := []interface{}{inst.account, inst.instanceID, inst.instanceName}
+		for _, c := range inst.counts {
+			values = append(values, c)
+		}
+		worstFinding := "-"
+		if inst.worstFinding != "" {
+			worstFinding = fmt.Sprintf("[%s] %s", inst.worstSeverity, inst.worstFinding)
+		}
+		values = append(values, inst.amiID, inst.asgName, inst.hostname, strings.Join(inst.ipAddresses, "\r\n"), worstFinding)
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &values)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("%s%d", lastCountCol, row), styles.defaultCentered)
+		if style := styles.severity(inst.worstSeverity); style != 0 {
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("%s%d", lastCol, row), fmt.Sprintf("%s%d", lastCol, row), style)
+		}
+	}
+	_ = xlsx.AutoFilter(sheetName, "A1", fmt.Sprintf("%s%d", lastCol, row), "")
+}
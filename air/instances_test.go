@@ -0,0 +1,31 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummariseInstances(t *testing.T) {
+	ar := testAccountsResults()
+	f := testFinding("CVE-2019-0003", "Medium", "i-0000000003", "api")
+	f.AssetAttributes.Hostname = ptrToStr("ip-10-0-0-3.ec2.internal")
+	f.AssetAttributes.Ipv4Addresses = []*string{ptrToStr("10.0.0.3")}
+	f.AssetAttributes.AmiId = ptrToStr("ami-0123456789")
+	ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings = append(ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings, f)
+	instances := summariseInstances(generateSpreadsheetData(ar))
+
+	assert.Len(t, instances, 3)
+	// i-0000000001 has one high and one medium
+	assert.Equal(t, "i-0000000001", instances[0].instanceID)
+	assert.Equal(t, []int{0, 1, 1, 0, 0, 0}, instances[0].counts)
+	assert.Equal(t, "HIGH", instances[0].worstSeverity)
+	assert.Equal(t, "CVE-2019-0001", instances[0].worstFinding)
+	// i-0000000002 has one high
+	assert.Equal(t, "i-0000000002", instances[1].instanceID)
+	// i-0000000003 has one medium and one low
+	assert.Equal(t, "i-0000000003", instances[2].instanceID)
+	assert.Equal(t, "acme-prod", instances[2].account)
+	assert.Equal(t, "MEDIUM", instances[2].worstSeverity)
+	assert.Equal(t, "CVE-2019-0003", instances[2].worstFinding)
+}
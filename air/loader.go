@@ -5,84 +5,49 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
-	yaml "gopkg.in/yaml.v2"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
 )
 
-func loadFilters(configPath string, debug bool) (filters filters) {
-	filtersPath := ensureTrailingSlash(configPath) + filtersFileName
-	var err error
+// parseConfigContent decodes the content of a configuration file using the same parser that validates it
+func parseConfigContent(content []byte, out interface{}) error {
+	return errors.WithStack(yaml.Unmarshal(content, out))
+}
 
-	// try loading from s3
-	if strings.HasPrefix(configPath, "s3://") {
-		// split config path (minus prefix)
-		parts := strings.Split(configPath[5:], "/")
-		key := ensureTrailingSlash(strings.Join(parts[1:], "/")) + filtersFileName
-		sess := session.Must(session.NewSession())
-		var region string
-		region, err = s3manager.GetBucketRegion(context.Background(), sess, parts[0], "us-east-1")
-		if err != nil && debug {
-			fmt.Println("failed to get bucket region")
-			os.Exit(1)
-		}
-		sess = session.Must(session.NewSession(&aws.Config{Region: ptrToStr(region)}))
-		svc := s3.New(sess)
-		input := &s3.GetObjectInput{
-			Bucket: aws.String(parts[0]),
-			Key:    aws.String(key),
-		}
-		var goo *s3.GetObjectOutput
-		goo, err = svc.GetObject(input)
-		if err != nil && debug {
-			fmt.Printf("failed to load filters from s3://%s/%s\n", parts[0], key)
-		}
-		if err == nil {
-			buf := new(bytes.Buffer)
-			_, err = buf.ReadFrom(goo.Body)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-			err = yaml.Unmarshal(buf.Bytes(), &filters)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-		}
-	} else {
-		// try loading from filesystem
-		if _, err = os.Stat(filtersPath); err == nil {
-			_, openErr := os.Open(filtersPath)
-			if openErr != nil && debug {
-				fmt.Println(err)
-			}
-			filtersFileContent, readErr := ioutil.ReadFile(filtersPath)
-			if readErr != nil && debug {
-				fmt.Println(err)
-			}
-			filters, err = parseFiltersFileContent(filtersFileContent)
-			if err != nil && debug {
-				fmt.Println(err)
-			}
-		} else if debug {
-			fmt.Println(err)
-		}
+func loadFilters(configPath string) (filters filters, err error) {
+	location, content, found, err := readConfigFile(configPath, filtersFileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", location)
 	}
-	return filters
+	if !found {
+		return nil, nil
+	}
+	if filters, err = parseFiltersFileContent(content); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", location)
+	}
+	return filters, nil
 }
 
-// try loading report configuration from envvars, then from path provided
-func loadReportConfig(configPath string, debug bool) (reportConfig Report) {
-	var err error
-	reportFilePath := ensureTrailingSlash(configPath) + reportFileName
-	// try loading from envvars (only AWS SES Supported so far)
+// load report configuration from the path provided, with any email settings in envvars taking precedence
+func loadReportConfig(configPath string) (reportConfig Report, err error) {
+	location, content, found, err := readConfigFile(configPath, reportFileName)
+	if err != nil {
+		return reportConfig, errors.Wrapf(err, "failed to read %s", location)
+	}
+	if found {
+		if err = parseConfigContent(content, &reportConfig); err != nil {
+			return Report{}, errors.Wrapf(err, "failed to parse %s", location)
+		}
+	}
+	// only the email settings can be set by envvars (only AWS SES Supported so far)
 	upper := strings.ToUpper
 	if upper(os.Getenv("AIR_EMAIL_PROVIDER")) == "SES" {
 		if os.Getenv("AIR_EMAIL_AWS_REGION") != "" &&
@@ -90,121 +55,159 @@ func loadReportConfig(configPath string, debug bool) (reportConfig Report) {
 			os.Getenv("AIR_EMAIL_RECIPIENTS") != "" &&
 			os.Getenv("AIR_EMAIL_SUBJECT") != "" {
 			recipients := strings.Split(os.Getenv("AIR_EMAIL_RECIPIENTS"), ",")
-			email := Email{
+			reportConfig.Email = Email{
 				Provider:   "ses",
 				Region:     os.Getenv("AIR_EMAIL_AWS_REGION"),
 				Source:     os.Getenv("AIR_EMAIL_SOURCE"),
 				Recipients: recipients,
 				Subject:    os.Getenv("AIR_EMAIL_SUBJECT"),
 			}
-			reportConfig.Email = email
-			return
 		}
 	}
-	// try loading from s3
+	return reportConfig, nil
+}
+
+func loadTargets(configPath string) (targets targets, err error) {
+	location, content, found, err := readConfigFile(configPath, targetsFileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", location)
+	}
+	if !found {
+		return nil, nil
+	}
+	if targets, err = parseTargetsFileContent(content); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", location)
+	}
+	return targets, nil
+}
+
+// readConfigFile returns the content of the named configuration file from the filesystem or AWS S3 path
+// found is false if the file does not exist
+func readConfigFile(configPath, fileName string) (location string, content []byte, found bool, err error) {
 	if strings.HasPrefix(configPath, "s3://") {
 		// split config path (minus prefix)
 		parts := strings.Split(configPath[5:], "/")
-		key := ensureTrailingSlash(strings.Join(parts[1:], "/")) + reportFileName
-		sess := session.Must(session.NewSession())
-		var region string
-		region, err = s3manager.GetBucketRegion(context.Background(), sess, parts[0], "us-east-1")
-		if err != nil && debug {
-			fmt.Println("failed to get bucket region")
-			os.Exit(1)
-		}
-		sess = session.Must(session.NewSession(&aws.Config{Region: ptrToStr(region)}))
-		svc := s3.New(sess)
-		input := &s3.GetObjectInput{
-			Bucket: aws.String(parts[0]),
-			Key:    aws.String(key),
-		}
-		var goo *s3.GetObjectOutput
-		goo, err = svc.GetObject(input)
-		if err != nil && debug {
-			fmt.Printf("failed to load report from s3://%s/%s\n", parts[0], key)
-			return
-		}
-		if goo == nil || goo.Body == nil {
-			return
-		}
-		buf := new(bytes.Buffer)
-		_, err = buf.ReadFrom(goo.Body)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		err = yaml.Unmarshal(buf.Bytes(), &reportConfig)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
+		key := ensureTrailingSlash(strings.Join(parts[1:], "/")) + fileName
+		location = fmt.Sprintf("s3://%s/%s", parts[0], key)
+		content, found, err = getS3Object(parts[0], key)
+		return location, content, found, err
+	}
+	location = ensureTrailingSlash(configPath) + fileName
+	content, found, err = readLocalFile(location)
+	return location, content, found, err
+}
+
+// readFile returns the content of a file on the filesystem or, if prefixed with s3://, in AWS S3
+// found is false if the file does not exist
+func readFile(path string) (content []byte, found bool, err error) {
+	if strings.HasPrefix(path, "s3://") {
+		bucket, key := splitS3Path(path)
+		return getS3Object(bucket, key)
+	}
+	return readLocalFile(path)
+}
+
+// writeFile writes the content to a file on the filesystem or, if prefixed with s3://, to AWS S3
+func writeFile(path string, content []byte) error {
+	if strings.HasPrefix(path, "s3://") {
+		bucket, key := splitS3Path(path)
+		return putS3Object(bucket, key, content)
+	}
+	return errors.WithStack(ioutil.WriteFile(path, content, 0644))
+}
+
+func readLocalFile(path string) (content []byte, found bool, err error) {
+	content, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	return content, err == nil, errors.WithStack(err)
+}
+
+// splitS3Path returns the bucket and key of a path in the form s3://bucket/key
+func splitS3Path(path string) (bucket, key string) {
+	parts := strings.SplitN(strings.TrimPrefix(path, "s3://"), "/", 2)
+	bucket = parts[0]
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	return
+}
+
+func getS3Client(bucket string) (*s3.S3, error) {
+	sess := session.Must(session.NewSession())
+	region, err := s3manager.GetBucketRegion(context.Background(), sess, bucket, "us-east-1")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sess = session.Must(session.NewSession(&aws.Config{Region: ptrToStr(region)}))
+	return s3.New(sess), nil
+}
+
+// getS3Object returns the content of the object, with found being false if it does not exist
+func getS3Object(bucket, key string) (content []byte, found bool, err error) {
+	svc, err := getS3Client(bucket)
+	if err != nil {
 		return
 	}
-	// try loading from file
-	if _, err = os.Stat(reportFilePath); err == nil {
-		_, err = os.Open(reportFilePath)
-		if err != nil && debug {
-			fmt.Println(err)
-		}
-		var reportFileContent []byte
-		reportFileContent, err = ioutil.ReadFile(reportFilePath)
-		if err != nil && debug {
-			fmt.Println(err)
-		}
-		err = yaml.Unmarshal(reportFileContent, &reportConfig)
-		if err != nil && debug {
-			fmt.Println(err)
-		}
-	} else if debug {
-		fmt.Println(err)
+	goo, err := svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if aErr, ok := err.(awserr.Error); ok && aErr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.WithStack(err)
 	}
-	return reportConfig
+	defer goo.Body.Close()
+	content, err = ioutil.ReadAll(goo.Body)
+	return content, err == nil, errors.WithStack(err)
 }
 
-func loadTargets(configPath string, debug bool) (targets targets) {
-	var err error
-	// try loading from s3
-	if strings.HasPrefix(configPath, "s3://") {
-		// split config path (minus prefix)
-		parts := strings.Split(configPath[5:], "/")
-		key := ensureTrailingSlash(strings.Join(parts[1:], "/")) + targetsFileName
-		sess := session.Must(session.NewSession())
-		var region string
-		region, err = s3manager.GetBucketRegion(context.Background(), sess, parts[0], "us-east-1")
+func putS3Object(bucket, key string, content []byte) error {
+	svc, err := getS3Client(bucket)
+	if err != nil {
+		return err
+	}
+	_, err = svc.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(content),
+	})
+	return errors.WithStack(err)
+}
+
+// listFiles returns the paths of the files with the extension in a directory on the filesystem or, if prefixed with s3://, under a prefix in AWS S3
+func listFiles(path, extension string) (paths []string, err error) {
+	if strings.HasPrefix(path, "s3://") {
+		bucket, prefix := splitS3Path(path)
+		var svc *s3.S3
+		svc, err = getS3Client(bucket)
 		if err != nil {
-			log.Fatal(err)
-		}
-		sess = session.Must(session.NewSession(&aws.Config{Region: ptrToStr(region)}))
-		svc := s3.New(sess)
-		input := &s3.GetObjectInput{
-			Bucket: aws.String(parts[0]),
-			Key:    aws.String(key),
-		}
-		var goo *s3.GetObjectOutput
-		goo, err = svc.GetObject(input)
-		if err != nil && debug {
-			fmt.Printf("failed to load targets from s3://%s/%s\n", parts[0], key)
+			return
 		}
-		if err == nil {
-			buf := new(bytes.Buffer)
-			_, err = buf.ReadFrom(goo.Body)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+		err = svc.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(prefix),
+		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, o := range page.Contents {
+				if strings.HasSuffix(*o.Key, "."+extension) {
+					paths = append(paths, fmt.Sprintf("s3://%s/%s", bucket, *o.Key))
+				}
 			}
-			err = yaml.Unmarshal(buf.Bytes(), &targets)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-		}
-	} else {
-		// try loading from filesystem
-		targets, err = readTargets(ensureTrailingSlash(configPath) + targetsFileName)
-		if err != nil && debug {
-			fmt.Println(err)
+			return true
+		})
+		return paths, errors.WithStack(err)
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), "."+extension) {
+			paths = append(paths, ensureTrailingSlash(path)+f.Name())
 		}
 	}
-	return targets
+	return paths, nil
 }
@@ -5,6 +5,8 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/sts"
 
@@ -12,13 +14,25 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh/terminal"
 
 	"os"
 )
 
 type Report struct {
-	Email Email
+	Email       Email
+	Spreadsheet Spreadsheet `yaml:"spreadsheet"`
+}
+
+// Spreadsheet has the settings that control the content of the generated spreadsheet
+type Spreadsheet struct {
+	// omit findings with a severity of 'ignore' instead of listing them on a separate sheet
+	ExcludeSuppressed bool `yaml:"excludeSuppressed"`
+	// fields to show on each account sheet, in order
+	Columns []Column `yaml:"columns"`
+	// number of days findings of each severity can remain open
+	SLA SLA `yaml:"sla"`
 }
 
 const (
@@ -27,9 +41,22 @@ const (
 	filtersFileName = "filters.yml"
 
 	DefaultMaxReportAge = 60
+
+	DefaultExpiryWarningDays = 14
+
+	DefaultAccountConcurrency = 4
+	DefaultRegionConcurrency  = 8
+
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatHTML = "html"
 )
 
+var supportedFormats = []string{FormatXLSX, FormatJSON, FormatCSV, FormatHTML}
+
 type AppConfig struct {
+	// show the stack trace of each error encountered retrieving findings
 	Debug        bool
 	TargetsFile  string
 	FiltersFile  string
@@ -40,15 +67,119 @@ type AppConfig struct {
 	targets      targets
 	report       Report
 	OutputDir    string
+	Formats      []string
+	Version      string
+	// number of days before a filter expires to start warning
+	ExpiryWarningDays int
+	// local path or s3://bucket/key of the file used to compare findings with those of the previous run
+	StatePath string
+	// path or s3://bucket/key of a JSON report to generate reports from instead of retrieving findings from Inspector
+	FromSnapshot string
+	// severity at or above which remaining findings cause Run to return a SeverityThresholdError
+	FailOn string
+	// version of Inspector to retrieve findings from, which can be overridden per target: classic (default), v2 or all
+	Inspector string
+	// maximum number of accounts, and regions within each account, to retrieve findings from at the same time
+	AccountConcurrency int
+	RegionConcurrency  int
+	// regions to retrieve findings from, defaulting to all those Inspector is available in, which can be overridden per target
+	Regions []string
+	// regions to never retrieve findings from
+	ExcludeRegions []string
+	// partition of the targets, which can be overridden per target: aws (default), aws-us-gov or aws-cn
+	Partition string
+	// AWS profiles, in the form <partition>=<profile>, providing the credentials used for targets in each partition
+	PartitionProfiles []string
+}
+
+// SeverityThresholdError is returned by Run when findings at or above the fail-on severity remain after filtering
+type SeverityThresholdError struct {
+	Severity string
+	Findings int
+}
+
+func (e SeverityThresholdError) Error() string {
+	return fmt.Sprintf("%d finding(s) at or above severity %s", e.Findings, e.Severity)
+}
+
+// CollectionError is returned by Run when findings could not be retrieved from one or more accounts
+type CollectionError struct {
+	Accounts int
+}
+
+func (e CollectionError) Error() string {
+	return fmt.Sprintf("errors encountered retrieving findings from %d account(s)", e.Accounts)
+}
+
+// load reads the targets, filters and report configuration files, any of which can be absent
+func (appConfig *AppConfig) load() (err error) {
+	loaded := *appConfig
+	if loaded.targets, err = loadTargets(appConfig.ConfigPath); err != nil {
+		return err
+	}
+	if loaded.filters, err = loadFilters(appConfig.ConfigPath); err != nil {
+		return err
+	}
+	if loaded.report, err = loadReportConfig(appConfig.ConfigPath); err != nil {
+		return err
+	}
+	// check the columns before retrieving any findings so invalid configuration fails early
+	if _, err = resolveColumns(loaded.report.Spreadsheet.Columns); err != nil {
+		return fmt.Errorf("%s: %s", reportFileName, err)
+	}
+	if err = loaded.report.Spreadsheet.SLA.check(); err != nil {
+		return fmt.Errorf("%s: %s", reportFileName, err)
+	}
+	*appConfig = loaded
+	return nil
 }
 
-func (appConfig *AppConfig) load() {
-	loaded := appConfig
+// formats returns the requested output formats, defaulting to a spreadsheet only
+func (appConfig *AppConfig) formats() (formats []string, err error) {
+	for _, f := range appConfig.Formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || stringInSlice(f, formats) {
+			continue
+		}
+		if !stringInSlice(f, supportedFormats) {
+			return nil, fmt.Errorf("output format '%s' not supported", f)
+		}
+		formats = append(formats, f)
+	}
+	if len(formats) == 0 {
+		formats = []string{FormatXLSX}
+	}
+	return
+}
 
-	loaded.targets = loadTargets(appConfig.ConfigPath, appConfig.Debug)
-	loaded.filters = loadFilters(appConfig.ConfigPath, appConfig.Debug)
-	loaded.report = loadReportConfig(appConfig.ConfigPath, appConfig.Debug)
-	*appConfig = *loaded
+// failOn returns the normalised fail-on severity, or an empty string if not set
+func (appConfig *AppConfig) failOn() (severity string, err error) {
+	severity = strings.ToUpper(strings.TrimSpace(appConfig.FailOn))
+	if severity == "" {
+		return
+	}
+	if severityRank[severity] == 0 {
+		return "", fmt.Errorf("fail-on severity '%s' not supported, valid severities are: CRITICAL, HIGH, MEDIUM, LOW, INFORMATIONAL", appConfig.FailOn)
+	}
+	return
+}
+
+// countAtOrAbove returns the number of findings with a severity at or above the one specified
+func (ar *accountsResults) countAtOrAbove(severity string) (count int) {
+	for _, r := range *ar {
+		for _, rr := range r.regionResults {
+			for _, rtr := range rr.regionTemplateResults {
+				for _, rtrr := range rtr.runs {
+					for _, f := range rtrr.findings {
+						if severityRank[strings.ToUpper(derefStr(f.Severity))] >= severityRank[severity] {
+							count++
+						}
+					}
+				}
+			}
+		}
+	}
+	return count
 }
 
 func (ar *accountsResults) hasFindings() bool {
@@ -75,31 +206,96 @@ func Run(appConfig AppConfig) error {
 		return err
 	}
 	var tems targetErrorsMaps
-	appConfig.load()
-	if len(appConfig.targets) > 0 {
-		accountsResults, tems, err = processMultipleAccounts(initialSess, appConfig.targets, appConfig.MaxReportAge)
-	} else {
-		accountsResults, tems, err = processSingleAccount(initialSess, appConfig.MaxReportAge)
+	var formats []string
+	formats, err = appConfig.formats()
+	if err != nil {
+		return err
+	}
+	var failOn string
+	failOn, err = appConfig.failOn()
+	if err != nil {
+		return err
+	}
+	var version string
+	version, err = inspectorVersion(appConfig.Inspector)
+	if err != nil {
+		return err
+	}
+	var opts collectionOptions
+	opts, err = appConfig.collectionOptions(version)
+	if err != nil {
+		return err
+	}
+	var profiles map[string]string
+	profiles, err = parsePartitionProfiles(appConfig.PartitionProfiles)
+	if err != nil {
+		return err
+	}
+	sessions := newPartitionSessions(initialSess, opts.partition, profiles)
+	if err = appConfig.load(); err != nil {
+		return err
+	}
+	if appConfig.report.Spreadsheet.SLA.defined() && (appConfig.StatePath == "" || appConfig.FromSnapshot != "") {
+		fmt.Println("Warning: SLAs are defined but findings are not being tracked with --state, so days open are counted from" +
+			" when each finding was created, which is reset by every assessment run and will understate how long findings have been open")
+	}
+	if appConfig.FromSnapshot == "" {
+		if err = appConfig.discoverTargets(sessions, opts.partition); err != nil {
+			return err
+		}
+		for _, w := range regionWarnings(opts, appConfig.targets) {
+			fmt.Printf("Warning: %s\n", w)
+		}
+	}
+	expiring := expiringFilters(appConfig.filters, time.Now(), appConfig.ExpiryWarningDays)
+	if len(expiring) > 0 {
+		fmt.Printf("Warning: the following filters have expired or expire within %d days...\n\n", appConfig.ExpiryWarningDays)
+		for _, f := range expiring {
+			fmt.Printf("  Filter: %s\n", strings.Join(f.criteria(), ", "))
+			fmt.Printf("  Expires: %s\n\n", f.describeExpiry())
+		}
+	}
+	for _, f := range appConfig.filters {
+		for _, patternErr := range f.invalidPatterns() {
+			fmt.Printf("Warning: filter '%s' will be ignored due to %s\n", strings.Join(f.criteria(), ", "), patternErr)
+		}
+	}
+	// errors retrieving findings are recorded against each target and returned as a CollectionError once reports are generated
+	switch {
+	case appConfig.FromSnapshot != "":
+		accountsResults, tems, err = loadSnapshotResults(appConfig.FromSnapshot)
+		if err != nil {
+			return err
+		}
+		if appConfig.StatePath != "" {
+			fmt.Println("state is not updated when generating reports from a snapshot")
+			appConfig.StatePath = ""
+		}
+	case len(appConfig.targets) > 0:
+		accountsResults, tems, _ = processMultipleAccounts(sessions, appConfig.targets, opts)
+	default:
+		accountsResults, tems, _ = processSingleAccount(sessions, opts)
 	}
 	clearConsoleLine()
 
 	// if we have results and filters defined, then apply filters
-	if accountsResults != nil && accountsResults.hasFindings() {
+	var usage filterUsages
+	if accountsResults.hasFindings() {
 		if len(appConfig.filters) > 0 {
-			accountsResults.filter(appConfig.filters)
-		}
-		// if we still have results, then output to spreadsheet
-		var reportPath string
-		if len(accountsResults) > 0 {
-			reportPath, err = generateSpreadsheet(accountsResults, appConfig.OutputDir)
-			if err != nil {
-				fmt.Println("failed to generate spreadsheet:", err)
-				os.Exit(1)
+			usage = accountsResults.filter(appConfig.filters)
+			if unused := usage.unused(); len(unused) > 0 {
+				fmt.Printf("The following %d of %d filters did not match any findings...\n\n", len(unused), len(usage))
+				for _, f := range unused {
+					fmt.Printf("  Filter: %s\n", strings.Join(f.criteria(), ", "))
+				}
+				fmt.Println()
 			}
-			if !reflect.DeepEqual(appConfig.report.Email, Email{}) {
-				if err = emailReport(initialSess, reportPath, appConfig.report.Email, false); err != nil {
-					return err
+			if expired := usage.expiredMatches(); len(expired) > 0 {
+				fmt.Printf("The following %d filters have expired but still match findings, which are no longer suppressed...\n\n", len(expired))
+				for _, fu := range expired {
+					fmt.Printf("  Filter: %s (%d findings)\n", strings.Join(fu.filter.criteria(), ", "), fu.expired)
 				}
+				fmt.Println()
 			}
 		}
 	} else {
@@ -107,6 +303,48 @@ func Run(appConfig AppConfig) error {
 		fmt.Println("No findings found.")
 	}
 
+	// reports are output even without findings so the run info, including any errors, is still reported
+	timeStamp := time.Now().UTC()
+	var changes *findingChanges
+	var state findingsState
+	if appConfig.StatePath != "" {
+		if changes, state, err = compareFindingsState(appConfig.StatePath, &accountsResults, tems, timeStamp); err != nil {
+			return err
+		}
+	}
+	info := newRunInfo(appConfig, accountsResults, tems, timeStamp)
+	info.filterUsage = usage
+	info.changes = changes
+	var reportPaths []string
+	for _, format := range formats {
+		var reportPath string
+		switch format {
+		case FormatXLSX:
+			reportPath, err = generateSpreadsheet(accountsResults, appConfig.report.Spreadsheet, info, appConfig.OutputDir)
+		case FormatJSON:
+			reportPath, err = generateJSON(accountsResults, tems, appConfig.OutputDir, timeStamp)
+		case FormatCSV:
+			reportPath, err = generateCSV(accountsResults, appConfig.OutputDir, timeStamp)
+		case FormatHTML:
+			reportPath, err = generateHTML(accountsResults, appConfig.OutputDir, timeStamp)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to generate %s report", format)
+		}
+		reportPaths = append(reportPaths, reportPath)
+	}
+	if !reflect.DeepEqual(appConfig.report.Email, Email{}) {
+		if err = emailReport(initialSess, reportPaths, appConfig.report.Email, false); err != nil {
+			return err
+		}
+	}
+	// the state is saved after the reports are delivered so a failed run doesn't lose the changes it would have reported
+	if appConfig.StatePath != "" {
+		if err = saveFindingsState(appConfig.StatePath, state); err != nil {
+			return err
+		}
+	}
+
 	if anyTargetErrors(tems) {
 		fmt.Printf("Errors encountered during processing...\n\n")
 		for _, t := range tems {
@@ -114,84 +352,310 @@ func Run(appConfig AppConfig) error {
 				fmt.Printf("Account: %s (%s)\n", t.target.ID, t.target.Alias)
 				for _, e := range t.errors {
 					fmt.Printf("  Issue: %s\n", e.desc)
-					fmt.Printf("  Detail: %s\n", e.err)
+					if appConfig.Debug {
+						fmt.Printf("  Detail: %+v\n", e.err)
+					} else {
+						fmt.Printf("  Detail: %s\n", e.err)
+					}
 				}
 			}
 		}
 	}
 
-	return err
+	// a breach of the severity threshold takes precedence over collection errors, which have been listed above
+	if failOn != "" {
+		if count := accountsResults.countAtOrAbove(failOn); count > 0 {
+			return SeverityThresholdError{Severity: failOn, Findings: count}
+		}
+	}
+	if anyTargetErrors(tems) {
+		var accounts int
+		for _, t := range tems {
+			if len(t.errors) > 0 {
+				accounts++
+			}
+		}
+		return CollectionError{Accounts: accounts}
+	}
+	return nil
+}
+
+// loadSnapshotResults returns the findings in a JSON report, as they were before any filters were applied
+func loadSnapshotResults(path string) (accountsResults accountsResults, tems targetErrorsMaps, err error) {
+	s, err := loadSnapshot(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.SchemaVersion < 2 {
+		fmt.Printf("Warning: snapshot schema version %d does not record severities before filtering, so findings filtered when it was generated keep their filtered severity\n", s.SchemaVersion)
+	}
+	s.removeFilters()
+	accountsResults = s.accountsResults()
+	// errors encountered when the snapshot was generated are reported again
+	errs := s.targetErrorsMaps()
+	for _, ar := range accountsResults {
+		tem := targetErrorsMap{target: target{ID: ar.accountID, Alias: ar.accountAlias}}
+		for i := range errs {
+			if errs[i].target.ID == ar.accountID {
+				tem.errors = errs[i].errors
+				errs = append(errs[:i], errs[i+1:]...)
+				break
+			}
+		}
+		tems = append(tems, tem)
+	}
+	// accounts that findings couldn't be retrieved from at all
+	tems = append(tems, errs...)
+	fmt.Printf("loaded findings generated at %s from %s\n", s.GeneratedAt.UTC().Format(time.ANSIC), path)
+	return accountsResults, tems, nil
+}
+
+// collectionOptions control how findings are retrieved from each account
+type collectionOptions struct {
+	version      string
+	maxReportAge int
+	// maximum number of accounts, and regions within each account, to retrieve findings from at the same time
+	accountConcurrency int
+	regionConcurrency  int
+	// regions to limit retrieval to, if any, and those to skip
+	regions        []string
+	excludeRegions []string
+	partition      string
+}
+
+func (appConfig *AppConfig) collectionOptions(version string) (opts collectionOptions, err error) {
+	opts = collectionOptions{
+		version:            version,
+		maxReportAge:       appConfig.MaxReportAge,
+		accountConcurrency: appConfig.AccountConcurrency,
+		regionConcurrency:  appConfig.RegionConcurrency,
+	}
+	if opts.accountConcurrency < 1 {
+		opts.accountConcurrency = DefaultAccountConcurrency
+	}
+	if opts.regionConcurrency < 1 {
+		opts.regionConcurrency = DefaultRegionConcurrency
+	}
+	if opts.regions, err = normaliseRegions(appConfig.Regions); err != nil {
+		return opts, err
+	}
+	if opts.excludeRegions, err = normaliseRegions(appConfig.ExcludeRegions); err != nil {
+		return opts, err
+	}
+	if opts.partition, err = partitionID(appConfig.Partition); err != nil {
+		return opts, err
+	}
+	return opts, nil
 }
 
-func processMultipleAccounts(sess *session.Session, targets targets, maxReportAge int) (accountsResults accountsResults, tems targetErrorsMaps, err error) {
-	for _, target := range targets {
-		var tem targetErrorsMap
-		tem.target = target
-		var shortAccountOutput string
-		if target.Alias != "" {
-			shortAccountOutput = target.Alias
-		} else {
-			shortAccountOutput = target.ID
+// forTarget applies the settings overridden by the target, returning the version of Inspector to retrieve findings from
+func (opts *collectionOptions) forTarget(target target) (version string, err error) {
+	version = opts.version
+	if target.Inspector != "" {
+		if version, err = inspectorVersion(target.Inspector); err != nil {
+			return
 		}
-		statusOutput := fmt.Sprintf("Processing: [%s]...", shortAccountOutput)
-		statusOutput = padToWidth(statusOutput, true)
-		width, _, _ := terminal.GetSize(0)
-		if len(statusOutput) == width {
-			fmt.Printf(statusOutput[0:width-3] + "   \r")
-		} else {
-			fmt.Print(statusOutput)
+	}
+	if target.Partition != "" {
+		if opts.partition, err = partitionID(target.Partition); err != nil {
+			return
+		}
+	}
+	if len(target.Regions) > 0 {
+		if opts.regions, err = normaliseRegions(target.Regions); err != nil {
+			return
 		}
+	} else if len(opts.regions) > 0 {
+		// the global regions can span partitions, so only those in the target's partition apply
+		// and if there are none, findings are retrieved from every region in the partition
+		opts.regions = regionsInPartition(opts.regions, opts.partition)
+	}
+	return version, nil
+}
 
-		var creds *credentials.Credentials
-		creds, err = getAssumeRoleCreds(getAssumeRoleCredsInput{
-			Sess:       sess,
-			AccountID:  target.ID,
-			RoleName:   target.RoleName,
-			ExternalID: target.RoleExternalID,
-		})
-		if err != nil {
-			aErr := annotatedError{
-				err:  err,
-				desc: fmt.Sprintf("failed to assume role: %s", genRoleArn(target.ID, target.RoleName)),
+// selectRegions returns the available regions to retrieve findings from, and any requested that aren't available
+func (opts collectionOptions) selectRegions(available []string) (selected, unavailable []string) {
+	return selectRegions(available, opts.regions, opts.excludeRegions)
+}
+
+// targetProcessor retrieves the findings of a target, returning nil results if none could be retrieved
+type targetProcessor func(target target) (*accountResults, targetErrorsMap, error)
+
+func processMultipleAccounts(sessions *partitionSessions, targets targets, opts collectionOptions) (accountsResults accountsResults, tems targetErrorsMaps, err error) {
+	return processTargets(targets, opts.accountConcurrency, func(target target) (*accountResults, targetErrorsMap, error) {
+		return processTarget(sessions, target, opts)
+	})
+}
+
+// processTargets processes up to the number of targets specified by concurrency at the same time
+// results are returned in the order of the targets so reports are the same regardless of which accounts complete first
+func processTargets(targets targets, concurrency int, process targetProcessor) (accountsResults accountsResults, tems targetErrorsMaps, err error) {
+	type targetResult struct {
+		output *accountResults
+		tem    targetErrorsMap
+		err    error
+	}
+	results := make([]targetResult, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	// the status line is overwritten by each update, so when processing accounts at the same time
+	// the number completed is shown instead of the account being processed, with updates serialised
+	var mu sync.Mutex
+	var completed int
+	for i := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if concurrency <= 1 {
+				printProcessingStatus(targets[i].name())
 			}
-			tem.errors = append(tem.errors, aErr)
-			if isUnrecoverable(err) {
-				tems = append(tems, tem)
-				continue
+			results[i].output, results[i].tem, results[i].err = process(targets[i])
+			if concurrency > 1 {
+				mu.Lock()
+				completed++
+				printStatus(fmt.Sprintf("Processed: %d of %d accounts...", completed, len(targets)))
+				mu.Unlock()
 			}
+		}(i)
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r.output != nil {
+			accountsResults = append(accountsResults, *r.output)
+		}
+		tems = append(tems, r.tem)
+		if r.err != nil {
+			err = r.err
+		}
+	}
+	return accountsResults, tems, err
+}
+
+func processTarget(sessions *partitionSessions, target target, opts collectionOptions) (*accountResults, targetErrorsMap, error) {
+	tem := targetErrorsMap{target: target}
+	version, err := opts.forTarget(target)
+	if err != nil {
+		tem.errors = append(tem.errors, annotatedError{err: err, desc: "invalid target configuration"})
+		return nil, tem, err
+	}
+	sess, err := sessions.session(opts.partition)
+	if err != nil {
+		tem.errors = append(tem.errors, annotatedError{err: err, desc: fmt.Sprintf("no credentials for partition: %s", opts.partition)})
+		return nil, tem, err
+	}
+	creds, err := getAssumeRoleCreds(getAssumeRoleCredsInput{
+		Sess:       sess,
+		Partition:  opts.partition,
+		AccountID:  target.ID,
+		RoleName:   target.RoleName,
+		ExternalID: target.RoleExternalID,
+	})
+	if err != nil {
+		aErr := annotatedError{
+			err:  err,
+			desc: fmt.Sprintf("failed to assume role: %s", genRoleArn(opts.partition, target.ID, target.RoleName)),
 		}
-		var accountOutput accountResults
-		accountOutput.accountID = target.ID
-		accountOutput.accountAlias = target.Alias
-		var perRegionResults []regionResult
-		inspectorRegions := getAllInspectorRegions()
+		// no region can be retrieved from without the role, so don't record an error for each of them too
+		tem.errors = append(tem.errors, aErr)
+		return nil, tem, err
+	}
+	accountOutput := accountResults{accountID: target.ID, accountAlias: target.Alias}
+	var regionErrs []annotatedError
+	classicRegions, v2Regions, _ := opts.inspectorRegions(version)
+	if len(classicRegions) == 0 && len(v2Regions) == 0 {
+		tem.errors = append(tem.errors, noRegionsError(version))
+		return nil, tem, tem.errors[len(tem.errors)-1].err
+	}
+	tem.addRegions(classicRegions, v2Regions)
+	accountOutput.regionResults, regionErrs = processRegions(creds, target.ID, classicRegions, v2Regions, opts)
+	tem.errors = append(tem.errors, regionErrs...)
+	if err = regionErrorsResult(accountOutput.regionResults, regionErrs); err != nil && isUnrecoverable(err) {
+		return nil, tem, err
+	}
+	return &accountOutput, tem, err
+}
+
+// noRegionsError is recorded against a target when none of the regions requested are available
+func noRegionsError(version string) annotatedError {
+	return annotatedError{
+		err:  fmt.Errorf("none of the regions requested are available for inspector version: %s", version),
+		desc: "no regions to retrieve findings from",
+	}
+}
 
-		perRegionResults, err = processAllRegions(creds, inspectorRegions, maxReportAge)
+// regionWarnings returns a warning for each requested region that findings can't be retrieved from
+// warnings for regions specified by a target are prefixed with its name
+func regionWarnings(opts collectionOptions, targets targets) (warnings []string) {
+	if len(targets) == 0 {
+		_, _, warnings = opts.inspectorRegions(opts.version)
+		return warnings
+	}
+	for _, t := range targets {
+		targetOpts := opts
+		version, err := targetOpts.forTarget(t)
 		if err != nil {
-			aErr := annotatedError{
-				err:  err,
-				desc: fmt.Sprintf("failed to get region results"),
+			// reported when the target is processed
+			continue
+		}
+		_, _, targetWarnings := targetOpts.inspectorRegions(version)
+		for _, w := range targetWarnings {
+			if len(t.Regions) > 0 {
+				w = fmt.Sprintf("%s: %s", t.name(), w)
 			}
-			tem.errors = append(tem.errors, aErr)
-			if isUnrecoverable(err) {
-				tems = append(tems, tem)
-				continue
+			warnings = appendUnique(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// regionErrorsResult returns the first error retrieving findings from the regions, if any
+// an unrecoverable error is returned in preference if no region succeeded, as the account cannot be reported on
+func regionErrorsResult(results []regionResult, errs []annotatedError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if len(results) == 0 {
+		for _, aErr := range errs {
+			if isUnrecoverable(aErr.err) {
+				return aErr.err
 			}
 		}
-		accountOutput.regionResults = perRegionResults
-		accountsResults = append(accountsResults, accountOutput)
-		tems = append(tems, tem)
 	}
-	return accountsResults, tems, err
+	return errs[0].err
+}
+
+// printProcessingStatus shows the account being processed on a single line that is overwritten by the next
+func printProcessingStatus(account string) {
+	printStatus(fmt.Sprintf("Processing: [%s]...", account))
+}
+
+// printStatus shows the status on a single line that is overwritten by the next
+func printStatus(statusOutput string) {
+	statusOutput = padToWidth(statusOutput, true)
+	width, _, _ := terminal.GetSize(0)
+	if len(statusOutput) == width {
+		fmt.Print(statusOutput[0:width-3] + "   \r")
+	} else {
+		fmt.Print(statusOutput)
+	}
 }
 
-func processSingleAccount(sess *session.Session, maxReportAge int) (accountsResults accountsResults, tems targetErrorsMaps, err error) {
-	inspectorRegions := getAllInspectorRegions()
+func processSingleAccount(sessions *partitionSessions, opts collectionOptions) (accountsResults accountsResults, tems targetErrorsMaps, err error) {
 	var tem targetErrorsMap
+	sess, err := sessions.session(opts.partition)
+	if err != nil {
+		tem.errors = append(tem.errors, annotatedError{err: err, desc: fmt.Sprintf("no credentials for partition: %s", opts.partition)})
+		return nil, append(tems, tem), err
+	}
 	svc := iam.New(sess)
 	stsSvc := sts.New(sess)
 	accountID := getAccountID(stsSvc)
 	accountAlias := getAccountAlias(svc)
+	tem.target = target{ID: accountID, Alias: accountAlias}
 	sessCreds, err := sess.Config.Credentials.Get()
 	if err != nil {
 		os.Exit(1)
@@ -199,35 +663,25 @@ func processSingleAccount(sess *session.Session, maxReportAge int) (accountsResu
 	var accountOutput accountResults
 	accountOutput.accountID = accountID
 	accountOutput.accountAlias = accountAlias
-	var shortAccountOutput string
-	if accountAlias != "" {
-		shortAccountOutput = accountAlias
-	} else {
-		shortAccountOutput = accountID
-	}
 	var perRegionResults []regionResult
 	creds := credentials.NewStaticCredentials(sessCreds.AccessKeyID,
 		sessCreds.SecretAccessKey, sessCreds.SessionToken)
-	statusOutput := fmt.Sprintf("Processing: [%s]...", shortAccountOutput)
-	statusOutput = padToWidth(statusOutput, true)
-	width, _, _ := terminal.GetSize(0)
-	if len(statusOutput) == width {
-		fmt.Printf(statusOutput[0:width-3] + "   \r")
+	if accountAlias != "" {
+		printProcessingStatus(accountAlias)
 	} else {
-		fmt.Print(statusOutput)
+		printProcessingStatus(accountID)
 	}
 
-	perRegionResults, err = processAllRegions(creds, inspectorRegions, maxReportAge)
-	if err != nil {
-		aErr := annotatedError{
-			err:  err,
-			desc: fmt.Sprintf("failed to get region results"),
-		}
-		tem.errors = append(tem.errors, aErr)
-		if isUnrecoverable(err) {
-			tems = append(tems, tem)
-		}
+	var regionErrs []annotatedError
+	classicRegions, v2Regions, _ := opts.inspectorRegions(opts.version)
+	if len(classicRegions) == 0 && len(v2Regions) == 0 {
+		tem.errors = append(tem.errors, noRegionsError(opts.version))
+		return nil, append(tems, tem), tem.errors[len(tem.errors)-1].err
 	}
+	tem.addRegions(classicRegions, v2Regions)
+	perRegionResults, regionErrs = processRegions(creds, accountID, classicRegions, v2Regions, opts)
+	tem.errors = append(tem.errors, regionErrs...)
+	err = regionErrorsResult(perRegionResults, regionErrs)
 	accountOutput.regionResults = perRegionResults
 	accountsResults = append(accountsResults, accountOutput)
 	tems = append(tems, tem)
@@ -0,0 +1,244 @@
+package air
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppConfigFormats(t *testing.T) {
+	formats, err := (&AppConfig{Formats: []string{"JSON", " csv", "json", ""}}).formats()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{FormatJSON, FormatCSV}, formats)
+
+	formats, err = (&AppConfig{}).formats()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{FormatXLSX}, formats)
+
+	_, err = (&AppConfig{Formats: []string{"pdf"}}).formats()
+	assert.Error(t, err)
+}
+
+func TestAppConfigFailOn(t *testing.T) {
+	severity, err := (&AppConfig{FailOn: " high"}).failOn()
+	assert.NoError(t, err)
+	assert.Equal(t, "HIGH", severity)
+
+	severity, err = (&AppConfig{}).failOn()
+	assert.NoError(t, err)
+	assert.Empty(t, severity)
+
+	_, err = (&AppConfig{FailOn: "ignore"}).failOn()
+	assert.Error(t, err)
+}
+
+func TestAppConfigLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// missing configuration files are not an error
+	appConfig := AppConfig{ConfigPath: dir}
+	assert.NoError(t, appConfig.load())
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, targetsFileName), []byte("- id: \"012345678901\"\n  alias: acme-prod\n"), 0600))
+	assert.NoError(t, appConfig.load())
+	assert.Len(t, appConfig.targets, 1)
+	assert.Equal(t, "acme-prod", appConfig.targets[0].Alias)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filtersFileName), []byte("- id: [unterminated\n"), 0600))
+	err = appConfig.load()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse")
+}
+
+func TestLoadReportConfigEmailEnvVars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, reportFileName), []byte(`email:
+  provider: ses
+  region: eu-west-1
+  source: reports@example.com
+  recipients: [team@example.com]
+  subject: from file
+spreadsheet:
+  excludeSuppressed: true
+  columns:
+    - field: severity
+  sla:
+    high: 14
+`), 0600))
+	for k, v := range map[string]string{
+		"AIR_EMAIL_PROVIDER":   "ses",
+		"AIR_EMAIL_AWS_REGION": "eu-west-2",
+		"AIR_EMAIL_SOURCE":     "air@example.com",
+		"AIR_EMAIL_RECIPIENTS": "a@example.com,b@example.com",
+		"AIR_EMAIL_SUBJECT":    "from env",
+	} {
+		assert.NoError(t, os.Setenv(k, v))
+		defer os.Unsetenv(k)
+	}
+
+	// the email settings are overridden and the spreadsheet settings are still loaded
+	report, err := loadReportConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Email{Provider: "ses", Region: "eu-west-2", Source: "air@example.com",
+		Recipients: []string{"a@example.com", "b@example.com"}, Subject: "from env"}, report.Email)
+	assert.True(t, report.Spreadsheet.ExcludeSuppressed)
+	assert.Equal(t, []Column{{Field: "severity"}}, report.Spreadsheet.Columns)
+	assert.Equal(t, 14, report.Spreadsheet.SLA.High)
+}
+
+func TestCountAtOrAbove(t *testing.T) {
+	ar := testAccountsResults()
+	assert.Equal(t, 2, ar.countAtOrAbove("HIGH"))
+	assert.Equal(t, 3, ar.countAtOrAbove("MEDIUM"))
+	assert.Equal(t, 4, ar.countAtOrAbove("LOW"))
+
+	ar.filter(filters{{TitleMatch: "^CVE-2019-0001", Severity: "ignore"}})
+	assert.Equal(t, 0, ar.countAtOrAbove("HIGH"))
+	assert.Equal(t, 2, ar.countAtOrAbove("INFORMATIONAL"))
+}
+
+func TestRunErrors(t *testing.T) {
+	assert.Equal(t, "3 finding(s) at or above severity HIGH", SeverityThresholdError{Severity: "HIGH", Findings: 3}.Error())
+	assert.Equal(t, "errors encountered retrieving findings from 2 account(s)", CollectionError{Accounts: 2}.Error())
+}
+
+func TestCollectionOptions(t *testing.T) {
+	opts, err := (&AppConfig{MaxReportAge: 30}).collectionOptions(InspectorV2)
+	assert.NoError(t, err)
+	assert.Equal(t, collectionOptions{version: InspectorV2, maxReportAge: 30,
+		accountConcurrency: DefaultAccountConcurrency, regionConcurrency: DefaultRegionConcurrency, partition: PartitionAWS}, opts)
+
+	opts, err = (&AppConfig{AccountConcurrency: 10, RegionConcurrency: 2}).collectionOptions(InspectorClassic)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, opts.accountConcurrency)
+	assert.Equal(t, 2, opts.regionConcurrency)
+
+	opts, err = (&AppConfig{Regions: []string{" EU-West-1", "", "us-east-1"}, ExcludeRegions: []string{""}}).collectionOptions(InspectorClassic)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, opts.regions)
+	assert.Empty(t, opts.excludeRegions)
+
+	_, err = (&AppConfig{ExcludeRegions: []string{"eu-west-9"}}).collectionOptions(InspectorClassic)
+	assert.EqualError(t, err, "region 'eu-west-9' not recognised")
+
+	_, err = (&AppConfig{Partition: "aws-iso"}).collectionOptions(InspectorClassic)
+	assert.Error(t, err)
+}
+
+func TestCollectionOptionsForTarget(t *testing.T) {
+	opts, err := (&AppConfig{Regions: []string{"eu-west-1"}}).collectionOptions(InspectorClassic)
+	assert.NoError(t, err)
+	targetOpts := opts
+	version, err := targetOpts.forTarget(target{Inspector: "all", Regions: []string{"us-gov-west-1"}, Partition: "AWS-US-GOV"})
+	assert.NoError(t, err)
+	assert.Equal(t, InspectorAll, version)
+	assert.Equal(t, []string{"us-gov-west-1"}, targetOpts.regions)
+	assert.Equal(t, PartitionGovCloud, targetOpts.partition)
+	// the options of other targets are unchanged
+	assert.Equal(t, []string{"eu-west-1"}, opts.regions)
+
+	// only the global regions in the target's partition apply, or all of its regions if there are none
+	opts.regions = []string{"eu-west-1", "us-gov-east-1"}
+	targetOpts = opts
+	_, err = targetOpts.forTarget(target{Partition: PartitionGovCloud})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-gov-east-1"}, targetOpts.regions)
+	targetOpts = opts
+	_, err = targetOpts.forTarget(target{Partition: PartitionChina})
+	assert.NoError(t, err)
+	assert.Empty(t, targetOpts.regions)
+	targetOpts = opts
+	_, err = targetOpts.forTarget(target{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eu-west-1"}, targetOpts.regions)
+
+	targetOpts = opts
+	_, err = targetOpts.forTarget(target{Partition: "aws-iso"})
+	assert.Error(t, err)
+}
+
+func TestRegionWarnings(t *testing.T) {
+	opts, err := (&AppConfig{Regions: []string{"eu-west-1", "eu-west-3"}}).collectionOptions(InspectorAll)
+	assert.NoError(t, err)
+	classic, v2, warnings := opts.inspectorRegions(opts.version)
+	assert.Equal(t, []string{"eu-west-1"}, classic)
+	assert.Equal(t, []string{"eu-west-1", "eu-west-3"}, v2)
+	assert.Equal(t, []string{"Inspector Classic is not available in requested region: eu-west-3"}, warnings)
+
+	assert.Equal(t, warnings, regionWarnings(opts, nil))
+	assert.Equal(t, []string{
+		"Inspector Classic is not available in requested region: eu-west-3",
+		"acme-prod: Inspector Classic is not available in requested region: us-gov-west-1",
+		"acme-prod: Inspector v2 is not available in requested region: us-gov-west-1",
+	}, regionWarnings(opts, targets{{ID: "012345678901"}, {ID: "987654321098", Alias: "acme-prod", Regions: []string{"us-gov-west-1"}}}))
+
+	// none of the requested regions are available
+	opts.regions = []string{"us-gov-west-1"}
+	classic, v2, _ = opts.inspectorRegions(opts.version)
+	assert.Empty(t, classic)
+	assert.Empty(t, v2)
+}
+
+func TestProcessTargets(t *testing.T) {
+	var ts targets
+	for _, id := range []string{"111111111111", "222222222222", "333333333333", "444444444444", "555555555555"} {
+		ts = append(ts, target{ID: id})
+	}
+	var running, maxRunning int32
+	process := func(target target) (*accountResults, targetErrorsMap, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		// later targets complete first
+		time.Sleep(time.Duration('6'-target.ID[0]) * 5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		tem := targetErrorsMap{target: target}
+		if target.ID == "333333333333" {
+			err := errors.New("AccessDenied")
+			tem.errors = append(tem.errors, annotatedError{err: err, desc: "failed to assume role"})
+			return nil, tem, err
+		}
+		return &accountResults{accountID: target.ID}, tem, nil
+	}
+	accountsResults, tems, err := processTargets(ts, 2, process)
+	assert.Error(t, err)
+	assert.True(t, maxRunning <= 2)
+	var ids []string
+	for _, ar := range accountsResults {
+		ids = append(ids, ar.accountID)
+	}
+	assert.Equal(t, []string{"111111111111", "222222222222", "444444444444", "555555555555"}, ids)
+	assert.Len(t, tems, 5)
+	for i, tem := range tems {
+		assert.Equal(t, ts[i].ID, tem.target.ID)
+	}
+	assert.Len(t, tems[2].errors, 1)
+}
+
+func TestRegionErrorsResult(t *testing.T) {
+	assert.NoError(t, regionErrorsResult([]regionResult{{region: "eu-west-1"}}, nil))
+	errs := []annotatedError{
+		{err: errors.New("AccessDeniedException"), region: "eu-west-1"},
+		{err: errors.New("ExpiredToken: token has expired"), region: "eu-west-2"},
+	}
+	// the account is reported on if any region succeeded
+	err := regionErrorsResult([]regionResult{{region: "eu-west-3"}}, errs)
+	assert.False(t, isUnrecoverable(err))
+	err = regionErrorsResult(nil, errs)
+	assert.True(t, isUnrecoverable(err))
+}
@@ -0,0 +1,291 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/pkg/errors"
+)
+
+const organizationFileName = "organization.yml"
+
+// organization defines how targets are discovered from the accounts of an AWS Organization
+type organization struct {
+	// role to assume in each account discovered
+	RoleName       string `yaml:"roleName"`
+	RoleExternalID string `yaml:"roleExternalId"`
+	// if specified, accounts must be one of those listed, within one of the OUs and have all of the tags
+	Include organizationSelector `yaml:"include"`
+	// accounts matching any of the selectors are excluded
+	Exclude organizationSelector `yaml:"exclude"`
+}
+
+// organizationSelector matches accounts by id, organizational unit (including any nested within it) or tag
+type organizationSelector struct {
+	Accounts []string `yaml:"accounts"`
+	OUs      []string `yaml:"ous"`
+	// tags in the form <key>=<value>, or <key> to match any value
+	Tags []string `yaml:"tags"`
+}
+
+func parseOrganizationContent(content []byte) (o *organization, err error) {
+	o = &organization{}
+	if err = parseConfigContent(content, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// loadOrganization returns the discovery settings in the config path, or nil if none are defined
+func loadOrganization(configPath string) (*organization, error) {
+	location, content, found, err := readConfigFile(configPath, organizationFileName)
+	if err != nil || !found {
+		return nil, err
+	}
+	o, err := parseOrganizationContent(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", location)
+	}
+	if o.RoleName == "" {
+		return nil, fmt.Errorf("%s: roleName not specified", location)
+	}
+	return o, nil
+}
+
+// discoverTargets adds the accounts of the organization, if discovery is configured, to the targets
+// targets defined in targets.yml take precedence over discovered accounts with the same id
+// the organization is expected to be in the default partition
+func (appConfig *AppConfig) discoverTargets(sessions *partitionSessions, partition string) error {
+	o, err := loadOrganization(appConfig.ConfigPath)
+	if err != nil || o == nil {
+		return err
+	}
+	sess, err := sessions.session(partition)
+	if err != nil {
+		return err
+	}
+	// Organizations is a global service with a single endpoint in each partition
+	svc := organizations.New(sess, &aws.Config{Region: ptrToStr(partitionRegions[partition])})
+	discovered, err := o.discoverTargets(svc)
+	if err != nil {
+		return errors.Wrap(err, "failed to discover accounts from AWS Organizations")
+	}
+	fmt.Printf("discovered %d account(s) from AWS Organizations\n", len(discovered))
+	return appConfig.addDiscoveredTargets(discovered)
+}
+
+// addDiscoveredTargets merges the discovered accounts with the targets
+// discovering no accounts is an error if there are no other targets, rather than reporting on the current account instead
+func (appConfig *AppConfig) addDiscoveredTargets(discovered targets) error {
+	if len(discovered) == 0 {
+		if len(appConfig.targets) == 0 {
+			return fmt.Errorf("no accounts discovered from AWS Organizations match the %s include and exclude settings", organizationFileName)
+		}
+		fmt.Printf("Warning: no accounts discovered from AWS Organizations match the %s include and exclude settings\n", organizationFileName)
+	}
+	appConfig.targets = mergeTargets(discovered, appConfig.targets)
+	return nil
+}
+
+// discoverTargets returns a target for each active account matching the selectors, ordered by alias
+func (o organization) discoverTargets(svc organizationsiface.OrganizationsAPI) (discovered targets, err error) {
+	accounts, err := listOrganizationAccounts(svc)
+	if err != nil {
+		return nil, err
+	}
+	var includeOUs, excludeOUs []string
+	if len(o.Include.OUs) > 0 {
+		if includeOUs, err = listOUAccountIDs(svc, o.Include.OUs); err != nil {
+			return nil, err
+		}
+	}
+	if len(o.Exclude.OUs) > 0 {
+		if excludeOUs, err = listOUAccountIDs(svc, o.Exclude.OUs); err != nil {
+			return nil, err
+		}
+	}
+	for _, account := range accounts {
+		id := derefStr(account.Id)
+		if derefStr(account.Status) != organizations.AccountStatusActive {
+			continue
+		}
+		if len(o.Include.Accounts) > 0 && !stringInSlice(id, o.Include.Accounts) {
+			continue
+		}
+		if len(o.Include.OUs) > 0 && !stringInSlice(id, includeOUs) {
+			continue
+		}
+		if stringInSlice(id, o.Exclude.Accounts) || stringInSlice(id, excludeOUs) {
+			continue
+		}
+		if len(o.Include.Tags) > 0 || len(o.Exclude.Tags) > 0 {
+			var tags map[string]string
+			if tags, err = listAccountTags(svc, id); err != nil {
+				return nil, err
+			}
+			if !matchesAllTags(tags, o.Include.Tags) || matchesAnyTag(tags, o.Exclude.Tags) {
+				continue
+			}
+		}
+		discovered = append(discovered, target{
+			ID:             id,
+			Alias:          derefStr(account.Name),
+			RoleName:       o.RoleName,
+			RoleExternalID: o.RoleExternalID,
+		})
+	}
+	sort.SliceStable(discovered, func(i, j int) bool {
+		return discovered[i].Alias < discovered[j].Alias
+	})
+	return discovered, nil
+}
+
+func listOrganizationAccounts(svc organizationsiface.OrganizationsAPI) (accounts []*organizations.Account, err error) {
+	var nextToken *string
+	for {
+		var lao *organizations.ListAccountsOutput
+		lao, err = svc.ListAccounts(&organizations.ListAccountsInput{NextToken: nextToken})
+		if err != nil {
+			return accounts, errors.WithStack(err)
+		}
+		accounts = append(accounts, lao.Accounts...)
+		if lao.NextToken == nil {
+			return accounts, nil
+		}
+		nextToken = lao.NextToken
+	}
+}
+
+// listOUAccountIDs returns the ids of the accounts in the organizational units and any nested within them
+func listOUAccountIDs(svc organizationsiface.OrganizationsAPI, parentIDs []string) (ids []string, err error) {
+	for _, parentID := range parentIDs {
+		var nextToken *string
+		for {
+			var lao *organizations.ListAccountsForParentOutput
+			lao, err = svc.ListAccountsForParent(&organizations.ListAccountsForParentInput{
+				ParentId:  ptrToStr(parentID),
+				NextToken: nextToken,
+			})
+			if err != nil {
+				return ids, errors.WithStack(err)
+			}
+			for _, a := range lao.Accounts {
+				ids = append(ids, derefStr(a.Id))
+			}
+			if lao.NextToken == nil {
+				break
+			}
+			nextToken = lao.NextToken
+		}
+		var children []string
+		nextToken = nil
+		for {
+			var louo *organizations.ListOrganizationalUnitsForParentOutput
+			louo, err = svc.ListOrganizationalUnitsForParent(&organizations.ListOrganizationalUnitsForParentInput{
+				ParentId:  ptrToStr(parentID),
+				NextToken: nextToken,
+			})
+			if err != nil {
+				return ids, errors.WithStack(err)
+			}
+			for _, ou := range louo.OrganizationalUnits {
+				children = append(children, derefStr(ou.Id))
+			}
+			if louo.NextToken == nil {
+				break
+			}
+			nextToken = louo.NextToken
+		}
+		if len(children) > 0 {
+			var childIDs []string
+			if childIDs, err = listOUAccountIDs(svc, children); err != nil {
+				return ids, err
+			}
+			ids = append(ids, childIDs...)
+		}
+	}
+	return ids, nil
+}
+
+func listAccountTags(svc organizationsiface.OrganizationsAPI, accountID string) (tags map[string]string, err error) {
+	tags = make(map[string]string)
+	var nextToken *string
+	for {
+		var lto *organizations.ListTagsForResourceOutput
+		lto, err = svc.ListTagsForResource(&organizations.ListTagsForResourceInput{
+			ResourceId: ptrToStr(accountID),
+			NextToken:  nextToken,
+		})
+		if err != nil {
+			return tags, errors.WithStack(err)
+		}
+		for _, t := range lto.Tags {
+			tags[derefStr(t.Key)] = derefStr(t.Value)
+		}
+		if lto.NextToken == nil {
+			return tags, nil
+		}
+		nextToken = lto.NextToken
+	}
+}
+
+// matchesTag returns true if the tags include the selector in the form <key>=<value>, or <key> to match any value
+func matchesTag(tags map[string]string, selector string) bool {
+	parts := strings.SplitN(selector, "=", 2)
+	value, ok := tags[parts[0]]
+	if !ok {
+		return false
+	}
+	return len(parts) == 1 || value == parts[1]
+}
+
+func matchesAllTags(tags map[string]string, selectors []string) bool {
+	for _, s := range selectors {
+		if !matchesTag(tags, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyTag(tags map[string]string, selectors []string) bool {
+	for _, s := range selectors {
+		if matchesTag(tags, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeTargets returns the discovered targets, replaced by any configured with the same id, followed by the remaining configured targets
+func mergeTargets(discovered, configured targets) (merged targets) {
+	overrides := make(map[string]target)
+	for _, t := range configured {
+		overrides[t.ID] = t
+	}
+	seen := make(map[string]bool)
+	for _, t := range discovered {
+		if override, ok := overrides[t.ID]; ok {
+			if override.Alias == "" {
+				override.Alias = t.Alias
+			}
+			if override.RoleName == "" {
+				override.RoleName = t.RoleName
+				override.RoleExternalID = t.RoleExternalID
+			}
+			t = override
+		}
+		seen[t.ID] = true
+		merged = append(merged, t)
+	}
+	for _, t := range configured {
+		if !seen[t.ID] {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,98 @@
+package air
+
+import (
+	"testing"
+
+	a "github.com/jonhadfield/aws-inspector-reporter/air/airtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func discoveredIDs(discovered targets) (ids []string) {
+	for _, t := range discovered {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func TestDiscoverTargets(t *testing.T) {
+	m := &a.MockOrganizationsClient{}
+
+	// all active accounts, ordered by name
+	o := organization{RoleName: "InspectorScan", RoleExternalID: "secret"}
+	discovered, err := o.discoverTargets(m)
+	assert.NoError(t, err)
+	assert.Equal(t, targets{
+		{ID: "222222222222", Alias: "dev", RoleName: "InspectorScan", RoleExternalID: "secret"},
+		{ID: "111111111111", Alias: "prod", RoleName: "InspectorScan", RoleExternalID: "secret"},
+		{ID: "333333333333", Alias: "staging", RoleName: "InspectorScan", RoleExternalID: "secret"},
+	}, discovered)
+
+	// accounts within an OU, including those nested
+	o.Include.OUs = []string{"ou-ab12-11111111"}
+	discovered, err = o.discoverTargets(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"222222222222", "333333333333"}, discoveredIDs(discovered))
+
+	// excluding a nested OU
+	o.Exclude.OUs = []string{"ou-ab12-22222222"}
+	discovered, err = o.discoverTargets(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"222222222222"}, discoveredIDs(discovered))
+
+	// tag selectors
+	o = organization{RoleName: "InspectorScan"}
+	o.Include.Tags = []string{"inspector=enabled"}
+	discovered, err = o.discoverTargets(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"111111111111", "333333333333"}, discoveredIDs(discovered))
+	o.Exclude.Tags = []string{"environment=prod"}
+	o.Exclude.Accounts = []string{"222222222222"}
+	discovered, err = o.discoverTargets(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"333333333333"}, discoveredIDs(discovered))
+}
+
+func TestMatchesTag(t *testing.T) {
+	tags := map[string]string{"environment": "prod"}
+	assert.True(t, matchesTag(tags, "environment=prod"))
+	assert.True(t, matchesTag(tags, "environment"))
+	assert.False(t, matchesTag(tags, "environment=dev"))
+	assert.False(t, matchesTag(tags, "owner"))
+}
+
+func TestMergeTargets(t *testing.T) {
+	discovered := targets{
+		{ID: "222222222222", Alias: "dev", RoleName: "InspectorScan"},
+		{ID: "111111111111", Alias: "prod", RoleName: "InspectorScan"},
+	}
+	configured := targets{
+		{ID: "111111111111", RoleName: "InspectorProd", RoleExternalID: "secret"},
+		{ID: "999999999999", Alias: "external", RoleName: "InspectorScan"},
+	}
+	assert.Equal(t, targets{
+		{ID: "222222222222", Alias: "dev", RoleName: "InspectorScan"},
+		{ID: "111111111111", Alias: "prod", RoleName: "InspectorProd", RoleExternalID: "secret"},
+		{ID: "999999999999", Alias: "external", RoleName: "InspectorScan"},
+	}, mergeTargets(discovered, configured))
+}
+
+func TestAddDiscoveredTargets(t *testing.T) {
+	// without other targets, the current account would be reported on instead
+	appConfig := AppConfig{}
+	assert.Error(t, appConfig.addDiscoveredTargets(nil))
+
+	appConfig.targets = targets{{ID: "012345678901"}}
+	assert.NoError(t, appConfig.addDiscoveredTargets(nil))
+	assert.Len(t, appConfig.targets, 1)
+
+	assert.NoError(t, appConfig.addDiscoveredTargets(targets{{ID: "987654321098"}}))
+	assert.Len(t, appConfig.targets, 2)
+}
+
+func TestParseOrganizationContent(t *testing.T) {
+	o, err := parseOrganizationContent([]byte("roleName: InspectorScan\ninclude:\n  ous:\n    - ou-ab12-11111111\nexclude:\n  tags:\n    - environment=sandbox\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "InspectorScan", o.RoleName)
+	assert.Equal(t, []string{"ou-ab12-11111111"}, o.Include.OUs)
+	assert.Equal(t, []string{"environment=sandbox"}, o.Exclude.Tags)
+}
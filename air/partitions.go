@@ -0,0 +1,114 @@
+package air
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PartitionAWS is the standard partition, used unless another is specified
+	PartitionAWS = endpoints.AwsPartitionID
+	// PartitionGovCloud is the AWS GovCloud (US) partition
+	PartitionGovCloud = endpoints.AwsUsGovPartitionID
+	// PartitionChina is the AWS China partition
+	PartitionChina = endpoints.AwsCnPartitionID
+)
+
+var supportedPartitions = []string{PartitionAWS, PartitionGovCloud, PartitionChina}
+
+// partitionRegions are the regions used to call global services, such as STS, IAM and Organizations, in each partition
+var partitionRegions = map[string]string{
+	PartitionAWS:      endpoints.UsEast1RegionID,
+	PartitionGovCloud: endpoints.UsGovWest1RegionID,
+	PartitionChina:    endpoints.CnNorth1RegionID,
+}
+
+// partitionID returns the normalised partition, defaulting to aws if not set
+func partitionID(partition string) (string, error) {
+	partition = strings.ToLower(strings.TrimSpace(partition))
+	if partition == "" {
+		return PartitionAWS, nil
+	}
+	if !stringInSlice(partition, supportedPartitions) {
+		return "", fmt.Errorf("partition '%s' not supported, valid partitions are: %s", partition, strings.Join(supportedPartitions, ", "))
+	}
+	return partition, nil
+}
+
+// parsePartitionProfiles returns the AWS profiles, by partition, specified in the form <partition>=<profile>
+func parsePartitionProfiles(values []string) (profiles map[string]string, err error) {
+	profiles = make(map[string]string)
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("malformed partition profile '%s', expected <partition>=<profile>", v)
+		}
+		var partition string
+		if partition, err = partitionID(parts[0]); err != nil {
+			return nil, err
+		}
+		profiles[partition] = strings.TrimSpace(parts[1])
+	}
+	return profiles, nil
+}
+
+// partitionSessions provides the base session used to assume roles in the targets of each partition
+// credentials for one partition are not valid in another, so each partition other than the default requires a profile
+type partitionSessions struct {
+	base             *session.Session
+	defaultPartition string
+	profiles         map[string]string
+
+	mu       sync.Mutex
+	sessions map[string]*session.Session
+}
+
+func newPartitionSessions(base *session.Session, defaultPartition string, profiles map[string]string) *partitionSessions {
+	return &partitionSessions{
+		base:             base,
+		defaultPartition: defaultPartition,
+		profiles:         profiles,
+		sessions:         make(map[string]*session.Session),
+	}
+}
+
+// session returns the session for the partition, with a region in the partition so the matching STS endpoint is used
+func (ps *partitionSessions) session(partition string) (*session.Session, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if sess, ok := ps.sessions[partition]; ok {
+		return sess, nil
+	}
+	region := aws.String(partitionRegions[partition])
+	var sess *session.Session
+	switch profile, ok := ps.profiles[partition]; {
+	case ok:
+		var err error
+		sess, err = session.NewSessionWithOptions(session.Options{
+			Profile:           profile,
+			SharedConfigState: session.SharedConfigEnable,
+			Config:            aws.Config{Region: region},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create session for partition %s using profile %s", partition, profile)
+		}
+	case partition == ps.defaultPartition:
+		sess = ps.base
+		if p, found := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), aws.StringValue(sess.Config.Region)); !found || p.ID() != partition {
+			sess = sess.Copy(&aws.Config{Region: region})
+		}
+	default:
+		return nil, fmt.Errorf("no profile specified for partition %s, credentials are required for each partition", partition)
+	}
+	ps.sessions[partition] = sess
+	return sess, nil
+}
@@ -0,0 +1,55 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionID(t *testing.T) {
+	partition, err := partitionID("")
+	assert.NoError(t, err)
+	assert.Equal(t, PartitionAWS, partition)
+	partition, err = partitionID(" AWS-CN ")
+	assert.NoError(t, err)
+	assert.Equal(t, PartitionChina, partition)
+	_, err = partitionID("aws-iso")
+	assert.EqualError(t, err, "partition 'aws-iso' not supported, valid partitions are: aws, aws-us-gov, aws-cn")
+}
+
+func TestParsePartitionProfiles(t *testing.T) {
+	profiles, err := parsePartitionProfiles([]string{"aws-us-gov=gov", " aws-cn = china", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{PartitionGovCloud: "gov", PartitionChina: "china"}, profiles)
+	_, err = parsePartitionProfiles([]string{"aws-us-gov"})
+	assert.EqualError(t, err, "malformed partition profile 'aws-us-gov', expected <partition>=<profile>")
+	_, err = parsePartitionProfiles([]string{"aws-iso=iso"})
+	assert.Error(t, err)
+}
+
+func TestGenRoleArn(t *testing.T) {
+	assert.Equal(t, "arn:aws:iam::012345678901:role/InspectorScan", genRoleArn("", "012345678901", "InspectorScan"))
+	assert.Equal(t, "arn:aws-us-gov:iam::012345678901:role/InspectorScan", genRoleArn(PartitionGovCloud, "012345678901", "InspectorScan"))
+	assert.Equal(t, "arn:aws-cn:iam::012345678901:role/InspectorScan", genRoleArn(PartitionChina, "012345678901", "InspectorScan"))
+}
+
+func TestPartitionSessions(t *testing.T) {
+	base := session.Must(session.NewSession(&aws.Config{Region: aws.String("eu-west-1")}))
+	sessions := newPartitionSessions(base, PartitionGovCloud, nil)
+
+	// the base session is used for the default partition, with a region in that partition
+	sess, err := sessions.session(PartitionGovCloud)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-gov-west-1", aws.StringValue(sess.Config.Region))
+
+	// credentials for other partitions must be provided by a profile
+	_, err = sessions.session(PartitionAWS)
+	assert.EqualError(t, err, "no profile specified for partition aws, credentials are required for each partition")
+
+	sessions = newPartitionSessions(base, PartitionAWS, nil)
+	sess, err = sessions.session(PartitionAWS)
+	assert.NoError(t, err)
+	assert.Equal(t, base, sess)
+}
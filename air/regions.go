@@ -0,0 +1,63 @@
+package air
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+// normaliseRegions returns the regions in lower case without any empty values, or an error if any are not recognised
+func normaliseRegions(regions []string) (normalised []string, err error) {
+	for _, r := range regions {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" || stringInSlice(r, normalised) {
+			continue
+		}
+		if err = checkRegion(r); err != nil {
+			return nil, err
+		}
+		normalised = append(normalised, r)
+	}
+	return normalised, nil
+}
+
+// checkRegion returns an error if the region is not in any partition known to the SDK
+func checkRegion(region string) error {
+	for _, p := range endpoints.DefaultPartitions() {
+		if _, ok := p.Regions()[region]; ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("region '%s' not recognised", region)
+}
+
+// regionsInPartition returns the regions that are in the partition
+func regionsInPartition(regions []string, partition string) (in []string) {
+	for _, r := range regions {
+		if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), r); ok && p.ID() == partition {
+			in = append(in, r)
+		}
+	}
+	return in
+}
+
+// selectRegions returns the available regions, limited to those in include if any are specified, without those in exclude
+// regions in include that aren't available are also returned so they can be reported
+func selectRegions(available, include, exclude []string) (selected, unavailable []string) {
+	for _, r := range include {
+		if !stringInSlice(r, available) {
+			unavailable = append(unavailable, r)
+		}
+	}
+	for _, r := range available {
+		if len(include) > 0 && !stringInSlice(r, include) {
+			continue
+		}
+		if stringInSlice(r, exclude) {
+			continue
+		}
+		selected = append(selected, r)
+	}
+	return selected, unavailable
+}
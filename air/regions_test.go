@@ -0,0 +1,35 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectRegions(t *testing.T) {
+	available := []string{"eu-west-1", "eu-west-2", "us-east-1", "us-west-2"}
+	selected, unavailable := selectRegions(available, nil, nil)
+	assert.Equal(t, available, selected)
+	assert.Empty(t, unavailable)
+	selected, unavailable = selectRegions(available, []string{"eu-west-2", "ap-south-1"}, nil)
+	assert.Equal(t, []string{"eu-west-2"}, selected)
+	assert.Equal(t, []string{"ap-south-1"}, unavailable)
+	selected, _ = selectRegions(available, nil, []string{"eu-west-2", "us-east-1"})
+	assert.Equal(t, []string{"eu-west-1", "us-west-2"}, selected)
+	selected, unavailable = selectRegions(available, []string{"eu-west-1"}, []string{"eu-west-1"})
+	assert.Empty(t, selected)
+	assert.Empty(t, unavailable)
+}
+
+func TestRegionsInPartition(t *testing.T) {
+	regions := []string{"eu-west-1", "us-gov-west-1", "cn-north-1", "us-east-1"}
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, regionsInPartition(regions, PartitionAWS))
+	assert.Equal(t, []string{"us-gov-west-1"}, regionsInPartition(regions, PartitionGovCloud))
+	assert.Empty(t, regionsInPartition([]string{"eu-west-1"}, PartitionChina))
+}
+
+func TestCheckRegion(t *testing.T) {
+	assert.NoError(t, checkRegion("eu-west-1"))
+	assert.NoError(t, checkRegion("us-gov-west-1"))
+	assert.EqualError(t, checkRegion("eu-west-9"), "region 'eu-west-9' not recognised")
+}
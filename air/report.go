@@ -11,6 +11,18 @@ import (
 	"github.com/360EntSecGroup-Skylar/excelize/v2"
 )
 
+// reportSeverities are the severities shown in report summaries, in order of precedence
+var reportSeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFORMATIONAL", "IGNORE"}
+
+// severityRank is the relative importance of each severity, higher being more severe
+var severityRank = map[string]int{
+	"INFORMATIONAL": 1,
+	"LOW":           2,
+	"MEDIUM":        3,
+	"HIGH":          4,
+	"CRITICAL":      5,
+}
+
 func generateAccountRegionXLSXData(accountResults accountResults) (data []dataRow) {
 
 	for _, regionResult := range accountResults.regionResults {
@@ -34,8 +46,35 @@ func generateAccountRegionXLSXData(accountResults accountResults) (data []dataRo
 					if f.AssetAttributes.AmiId != nil {
 						dr.amiID = *f.AssetAttributes.AmiId
 					}
+					if f.AssetAttributes.Hostname != nil {
+						dr.hostname = *f.AssetAttributes.Hostname
+					}
+					for _, ip := range f.AssetAttributes.Ipv4Addresses {
+						dr.ipAddresses = append(dr.ipAddresses, *ip)
+					}
 					dr.template = r.templateArn
+					dr.runArn = run.runArn
+					if f.Arn != nil {
+						dr.findingArn = *f.Arn
+					}
+					if f.NumericSeverity != nil {
+						dr.numericSeverity = *f.NumericSeverity
+					}
+					if f.Confidence != nil {
+						dr.confidence = *f.Confidence
+					}
 					dr.comment = f.comment
+					dr.matchedFilter = f.matchedFilter
+					dr.expiredFilter = f.expiredFilter
+					dr.status = f.status
+					dr.firstSeen = f.firstSeen
+					dr.resourceType = strings.Join(attributeValues(f, attributeResourceType), ", ")
+					dr.vulnerabilityID = strings.Join(attributeValues(f, attributeVulnerabilityID), ", ")
+					dr.cvssScore = strings.Join(attributeValues(f, attributeCVSSScore), ", ")
+					dr.fixAvailable = strings.Join(attributeValues(f, attributeFixAvailable), ", ")
+					dr.packages = attributeValues(f, attributePackage)
+					dr.openPorts = strings.Join(attributeValues(f, attributeOpenPorts), ", ")
+					dr.networkPath = strings.Join(attributeValues(f, attributeNetworkPath), ", ")
 					dr.description = formatDescription(*f.Description)
 					dr.recommendation = formatRecommendation(*f.Recommendation)
 					if f.AssetAttributes.AutoScalingGroup != nil {
@@ -49,139 +88,280 @@ func generateAccountRegionXLSXData(accountResults accountResults) (data []dataRo
 		}
 	}
 
-	sevLookup := map[string]int{}
-	sevLookup["INFORMATIONAL"] = 1
-	sevLookup["LOW"] = 2
-	sevLookup["MEDIUM"] = 3
-	sevLookup["HIGH"] = 4
 	sort.Slice(data, func(i, j int) bool {
-		return sevLookup[data[i].severity] > sevLookup[data[j].severity]
+		return severityRank[data[i].severity] > severityRank[data[j].severity]
 	})
 
 	return data
 }
 
 type dataRow struct {
-	createdAt      time.Time
-	template       string
-	region         string
-	templateName   string
-	packageArn     string
-	packageName    string
-	severity       string
-	findingTitle   string
-	instanceID     string
-	instanceName   string
-	amiID          string
-	asgName        string
-	description    string
-	recommendation string
-	comment        string
+	createdAt       time.Time
+	template        string
+	runArn          string
+	findingArn      string
+	region          string
+	templateName    string
+	packageArn      string
+	packageName     string
+	severity        string
+	numericSeverity float64
+	confidence      int64
+	findingTitle    string
+	instanceID      string
+	instanceName    string
+	amiID           string
+	hostname        string
+	ipAddresses     []string
+	asgName         string
+	description     string
+	recommendation  string
+	comment         string
+	matchedFilter   *filter
+	expiredFilter   *filter
+	status          string
+	firstSeen       time.Time
+	daysOpen        int
+	slaStatus       string
+	// only set for Inspector v2 findings, or classic findings with the equivalent attributes
+	resourceType    string
+	vulnerabilityID string
+	cvssScore       string
+	fixAvailable    string
+	packages        []string
+	openPorts       string
+	networkPath     string
 }
 
-func generateSpreadsheet(accountsResults accountsResults, outputDir string) (string, error) {
-	xlsx := excelize.NewFile()
+// hasExpiredSuppressions returns true if any findings were matched by a filter that has expired
+func hasExpiredSuppressions(data []accountSpreadsheetData) bool {
+	for _, ad := range data {
+		for _, dr := range ad.rows {
+			if dr.expiredFilter != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasInspector2Data returns true if any of the rows have details only reported by Inspector v2
+func hasInspector2Data(data []accountSpreadsheetData) bool {
+	for _, ad := range data {
+		for _, dr := range ad.rows {
+			if dr.resourceType != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	var headerStyle, highResultStyle, mediumResultStyle, lowResultStyle, infoResultStyle, ignoredResultStyle, defaultCenteredStyle int
-	headerStyle, _ = xlsx.NewStyle(`{"fill":{"type":"pattern","color":["#000066"],"pattern":1},"font":{"bold":true,"italic":false,"family":"Calibri","size":14,"color":"#f2f2f2"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
-	highResultStyle, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#cc0000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
-	mediumResultStyle, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#cc6600"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
-	lowResultStyle, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#003399"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
-	infoResultStyle, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#000000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
-	ignoredResultStyle, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#000000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
-	defaultCenteredStyle, _ = xlsx.NewStyle(`{"font":{"bold":false,"italic":false,"family":"Calibri","size":12,"color":"#000000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":true}}`)
-	var firstSheet bool
+type spreadsheetStyles struct {
+	header          int
+	critical        int
+	high            int
+	medium          int
+	low             int
+	info            int
+	ignored         int
+	defaultCentered int
+	bold            int
+}
+
+func newSpreadsheetStyles(xlsx *excelize.File) (styles spreadsheetStyles) {
+	styles.header, _ = xlsx.NewStyle(`{"fill":{"type":"pattern","color":["#000066"],"pattern":1},"font":{"bold":true,"italic":false,"family":"Calibri","size":14,"color":"#f2f2f2"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.critical, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#800000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.high, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#cc0000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.medium, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#cc6600"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.low, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#003399"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.info, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#000000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.ignored, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#000000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":false}}`)
+	styles.defaultCentered, _ = xlsx.NewStyle(`{"font":{"bold":false,"italic":false,"family":"Calibri","size":12,"color":"#000000"},"alignment":{"horizontal":"center","ident":1,"justify_last_line":true,"reading_order":0,"relative_indent":1,"shrink_to_fit":true,"vertical":"","wrap_text":true}}`)
+	styles.bold, _ = xlsx.NewStyle(`{"font":{"bold":true,"italic":false,"family":"Calibri","size":12,"color":"#000000"}}`)
+	return
+}
+
+// severity returns the style for a severity cell, or zero if the severity is unknown
+func (styles spreadsheetStyles) severity(severity string) int {
+	switch severity {
+	case "CRITICAL":
+		return styles.critical
+	case "HIGH":
+		return styles.high
+	case "MEDIUM":
+		return styles.medium
+	case "LOW":
+		return styles.low
+	case "INFORMATIONAL":
+		return styles.info
+	case "IGNORE":
+		return styles.ignored
+	}
+	return 0
+}
+
+type accountSpreadsheetData struct {
+	accountID    string
+	accountAlias string
+	sheetName    string
+	rows         []dataRow
+}
+
+func generateSpreadsheetData(accountsResults accountsResults) (data []accountSpreadsheetData) {
 	for _, accountResults := range accountsResults {
-		accountSpreadsheetData := generateAccountRegionXLSXData(accountResults)
-		if len(accountSpreadsheetData) == 0 {
+		rows := generateAccountRegionXLSXData(accountResults)
+		if len(rows) == 0 {
 			continue
 		}
 		sheetName := accountResults.accountAlias
-		if !firstSheet {
-			firstSheet = true
-			xlsx.SetSheetName(xlsx.GetSheetName(1), sheetName)
-		} else {
-			_ = xlsx.NewSheet(accountResults.accountAlias)
+		if sheetName == "" {
+			sheetName = accountResults.accountID
 		}
+		data = append(data, accountSpreadsheetData{
+			accountID:    accountResults.accountID,
+			accountAlias: accountResults.accountAlias,
+			sheetName:    sheetName,
+			rows:         rows,
+		})
+	}
+	return data
+}
 
-		_ = xlsx.SetCellValue(sheetName, "A1", "SEVERITY")
-		_ = xlsx.SetCellValue(sheetName, "B1", "REGION")
-		_ = xlsx.SetCellValue(sheetName, "C1", "TEMPLATE")
-		_ = xlsx.SetCellValue(sheetName, "D1", "DATE")
-		_ = xlsx.SetCellValue(sheetName, "E1", "INSTANCE ID")
-		_ = xlsx.SetCellValue(sheetName, "F1", "INSTANCE NAME")
-		_ = xlsx.SetCellValue(sheetName, "G1", "ASG")
-		_ = xlsx.SetCellValue(sheetName, "H1", "RULES PACKAGE")
-		_ = xlsx.SetCellValue(sheetName, "I1", "TITLE")
-		_ = xlsx.SetCellValue(sheetName, "J1", "DESCRIPTION")
-		_ = xlsx.SetCellValue(sheetName, "K1", "RECOMMENDATION")
-		_ = xlsx.SetCellStyle(sheetName, "A1", "K1", headerStyle)
-		_ = xlsx.SetColWidth(sheetName, "A", "A", 15)
-		_ = xlsx.SetColWidth(sheetName, "B", "B", 13.5)
-		_ = xlsx.SetColWidth(sheetName, "C", "C", 26)
-		_ = xlsx.SetColWidth(sheetName, "D", "D", 22.5)
-		_ = xlsx.SetColWidth(sheetName, "E", "E", 19)
-		_ = xlsx.SetColWidth(sheetName, "F", "F", 24)
-		_ = xlsx.SetColWidth(sheetName, "G", "G", 20)
-		_ = xlsx.SetColWidth(sheetName, "H", "H", 44)
-		_ = xlsx.SetColWidth(sheetName, "I", "I", 60)
-		_ = xlsx.SetColWidth(sheetName, "J", "J", 70)
-		_ = xlsx.SetColWidth(sheetName, "K", "K", 150)
-		var lastRow string
-		for i, dataRow := range accountSpreadsheetData {
-			rowNum := i + 2
-			strRowNum := strconv.Itoa(rowNum)
-			resultCell := "A" + strRowNum
-			regionCell := "B" + strRowNum
-			templateCell := "C" + strRowNum
-			dateCell := "D" + strRowNum
-			instanceIDCell := "E" + strRowNum
-			instanceNameCell := "F" + strRowNum
-			instanceASGCell := "G" + strRowNum
-			rulesPackageCell := "H" + strRowNum
-			findingTitleCell := "I" + strRowNum
-			descriptionCell := "J" + strRowNum
-			recommendationCell := "K" + strRowNum
-			_ = xlsx.SetCellValue(sheetName, resultCell, dataRow.severity)
-			switch dataRow.severity {
-			case "HIGH":
-				_ = xlsx.SetCellStyle(sheetName, "A"+strRowNum, "A"+strRowNum, highResultStyle)
-			case "MEDIUM":
-				_ = xlsx.SetCellStyle(sheetName, "A"+strRowNum, "A"+strRowNum, mediumResultStyle)
-			case "LOW":
-				_ = xlsx.SetCellStyle(sheetName, "A"+strRowNum, "A"+strRowNum, lowResultStyle)
-			case "INFORMATIONAL":
-				_ = xlsx.SetCellStyle(sheetName, "A"+strRowNum, "A"+strRowNum, infoResultStyle)
-			case "IGNORE":
-				_ = xlsx.SetCellStyle(sheetName, "A"+strRowNum, "A"+strRowNum, ignoredResultStyle)
+func generateSpreadsheet(accountsResults accountsResults, config Spreadsheet, info runInfo, outputDir string) (string, error) {
+	columns, err := resolveColumns(config.Columns)
+	if err != nil {
+		return "", err
+	}
+	data := generateSpreadsheetData(accountsResults)
+	applySLA(data, config.SLA, info.timeStamp)
+	// fields only known when comparing with a previous run, when SLAs are defined or when filters have expired
+	// are added after the severity by default
+	if len(config.Columns) == 0 {
+		var extra []Column
+		if info.changes != nil {
+			extra = append(extra, Column{Field: "status"})
+		}
+		if config.SLA.defined() {
+			extra = append(extra, Column{Field: "days-open"}, Column{Field: "sla"})
+		}
+		if hasExpiredSuppressions(data) {
+			extra = append(extra, Column{Field: "suppression-expired"})
+		}
+		if len(extra) > 0 {
+			extra, _ = resolveColumns(extra)
+			columns = append(columns[:1], append(extra, columns[1:]...)...)
+		}
+	}
+	xlsx := excelize.NewFile()
+	styles := newSpreadsheetStyles(xlsx)
+	// details of Inspector v2 findings are added after the title by default
+	if len(config.Columns) == 0 && hasInspector2Data(data) {
+		extra, _ := resolveColumns([]Column{{Field: "resource-type"}, {Field: "vulnerability-id"}, {Field: "cvss"}, {Field: "fix-available"}})
+		for i, c := range columns {
+			if c.Field == "title" {
+				columns = append(columns[:i+1], append(extra, columns[i+1:]...)...)
+				break
 			}
-			if dataRow.comment != "" {
-				comment := fmt.Sprintf("{\"author\":\"%s\",\"text\":\" %s\"}", "-", dataRow.comment)
-				_ = xlsx.AddComment(sheetName, "A"+strRowNum, comment)
+		}
+	}
+	active, suppressed := separateSuppressed(data)
+	if config.ExcludeSuppressed {
+		data = active
+		suppressed = nil
+	}
+	xlsx.SetSheetName(xlsx.GetSheetName(1), summarySheetName)
+	addSummarySheet(xlsx, styles, generateSummary(data))
+	if info.changes != nil {
+		addChangesSheet(xlsx, styles, *info.changes)
+	}
+	addByFindingSheet(xlsx, styles, groupFindings(active))
+	addInstancesSheet(xlsx, styles, summariseInstances(active))
+	for _, accountData := range active {
+		_ = xlsx.NewSheet(accountData.sheetName)
+		addAccountSheet(xlsx, styles, accountData.sheetName, columns, accountData.rows)
+	}
+	if len(suppressed) > 0 {
+		addSuppressedSheet(xlsx, styles, suppressed)
+	}
+	if len(info.filterUsage) > 0 {
+		addFilterUsageSheet(xlsx, styles, info.filterUsage, accountsResults)
+	}
+	addRunInfoSheet(xlsx, styles, info)
+	xlsx.SetActiveSheet(xlsx.GetSheetIndex(summarySheetName))
+
+	path := getReportPath(outputDir, info.timeStamp, FormatXLSX)
+	err = xlsx.SaveAs(path)
+	if err != nil {
+		fmt.Println(err)
+		return "", err
+	}
+	absPath, _ := filepath.Abs(path)
+	fmt.Println("report written to:", absPath)
+	return absPath, err
+}
+
+func addAccountSheet(xlsx *excelize.File, styles spreadsheetStyles, sheetName string, columns []Column, accountSpreadsheetData []dataRow) {
+	header := make([]interface{}, 0, len(columns))
+	for i, c := range columns {
+		header = append(header, c.Header)
+		colName, _ := excelize.ColumnNumberToName(i + 1)
+		_ = xlsx.SetColWidth(sheetName, colName, colName, c.Width)
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(columns))
+	_ = xlsx.SetSheetRow(sheetName, "A1", &header)
+	_ = xlsx.SetCellStyle(sheetName, "A1", lastCol+"1", styles.header)
+	var lastRow string
+	for i, dataRow := range accountSpreadsheetData {
+		strRowNum := strconv.Itoa(i + 2)
+		values := make([]interface{}, 0, len(columns))
+		for _, c := range columns {
+			values = append(values, columnDefinitions[c.Field].value(dataRow))
+		}
+		_ = xlsx.SetSheetRow(sheetName, "A"+strRowNum, &values)
+		for ci, c := range columns {
+			colName, _ := excelize.ColumnNumberToName(ci + 1)
+			cell := colName + strRowNum
+			switch c.Field {
+			case "severity":
+				if style := styles.severity(dataRow.severity); style != 0 {
+					_ = xlsx.SetCellStyle(sheetName, cell, cell, style)
+				}
+				if dataRow.comment != "" {
+					comment := fmt.Sprintf("{\"author\":\"%s\",\"text\":\" %s\"}", "-", dataRow.comment)
+					_ = xlsx.AddComment(sheetName, cell, comment)
+				}
+			case "sla":
+				switch dataRow.slaStatus {
+				case slaStatusBreached:
+					_ = xlsx.SetCellStyle(sheetName, cell, cell, styles.high)
+				case slaStatusDueSoon:
+					_ = xlsx.SetCellStyle(sheetName, cell, cell, styles.medium)
+				default:
+					_ = xlsx.SetCellStyle(sheetName, cell, cell, styles.defaultCentered)
+				}
+			case "instance-id":
+				// set AMI as comment on instance cell if found
+				if dataRow.amiID != "" {
+					instComment := fmt.Sprintf("{\"author\":\"%s\",\"text\":\" %s\"}", "AMI:", dataRow.amiID)
+					_ = xlsx.AddComment(sheetName, cell, instComment)
+				}
 			}
-			_ = xlsx.SetCellValue(sheetName, regionCell, dataRow.region)
-			_ = xlsx.SetCellValue(sheetName, templateCell, dataRow.templateName)
-			_ = xlsx.SetCellValue(sheetName, dateCell, dataRow.createdAt.Format(time.ANSIC))
-			_ = xlsx.SetCellValue(sheetName, instanceIDCell, dataRow.instanceID)
-			// set AMI as comment on instance cell if found
-			if dataRow.amiID != "" {
-				instComment := fmt.Sprintf("{\"author\":\"%s\",\"text\":\" %s\"}", "AMI:", dataRow.amiID)
-				_ = xlsx.AddComment(sheetName, instanceIDCell, instComment)
+			if columnDefinitions[c.Field].centered {
+				_ = xlsx.SetCellStyle(sheetName, cell, cell, styles.defaultCentered)
 			}
-			_ = xlsx.SetCellValue(sheetName, instanceNameCell, dataRow.instanceName)
-			_ = xlsx.SetCellValue(sheetName, rulesPackageCell, dataRow.packageName)
-			_ = xlsx.SetCellValue(sheetName, instanceASGCell, dataRow.asgName)
-			_ = xlsx.SetCellValue(sheetName, findingTitleCell, dataRow.findingTitle)
-			_ = xlsx.SetCellValue(sheetName, descriptionCell, dataRow.description)
-			_ = xlsx.SetCellValue(sheetName, recommendationCell, dataRow.recommendation)
-			_ = xlsx.SetCellStyle(sheetName, "B"+strRowNum, "B"+strRowNum, defaultCenteredStyle)
-			_ = xlsx.SetCellStyle(sheetName, "E"+strRowNum, "G"+strRowNum, defaultCenteredStyle)
-			lastRow = strRowNum
 		}
-		_ = xlsx.AutoFilter(sheetName, "A1", "H"+lastRow, "")
+		lastRow = strRowNum
 	}
+	_ = xlsx.AutoFilter(sheetName, "A1", lastCol+lastRow, "")
+}
 
-	timeStamp := time.Now().UTC().Format("20060102150405")
+func getReportPath(outputDir string, timeStamp time.Time, extension string) string {
+	return getOutputPath(outputDir, "inspector_report", timeStamp, extension)
+}
+
+// getOutputPath returns the path of a file in the output directory named using the prefix and timestamp
+func getOutputPath(outputDir, prefix string, timeStamp time.Time, extension string) string {
 	var pathPrefix string
 	if outputDir != "" {
 		pathPrefix = outputDir
@@ -189,13 +369,5 @@ func generateSpreadsheet(accountsResults accountsResults, outputDir string) (str
 			pathPrefix = outputDir + string(filepath.Separator)
 		}
 	}
-	path := fmt.Sprintf("%sinspector_report_%s.xlsx", pathPrefix, timeStamp)
-	err := xlsx.SaveAs(path)
-	if err != nil {
-		fmt.Println(err)
-		return "", err
-	}
-	absPath, _ := filepath.Abs(path)
-	fmt.Println("report written to:", absPath)
-	return absPath, err
+	return fmt.Sprintf("%s%s_%s.%s", pathPrefix, prefix, timeStamp.UTC().Format("20060102150405"), extension)
 }
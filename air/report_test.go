@@ -0,0 +1,175 @@
+package air
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSpreadsheet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	info := runInfo{timeStamp: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)}
+	path, err := generateSpreadsheet(testAccountsResults(), Spreadsheet{}, info, dir)
+	assert.NoError(t, err)
+
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, summarySheetName, xlsx.GetSheetName(1))
+	assert.Equal(t, byFindingSheetName, xlsx.GetSheetName(2))
+	assert.Equal(t, instancesSheetName, xlsx.GetSheetName(3))
+	assert.Equal(t, "acme-nonprod", xlsx.GetSheetName(4))
+	assert.Equal(t, "acme-prod", xlsx.GetSheetName(5))
+	assert.Equal(t, runInfoSheetName, xlsx.GetSheetName(6))
+
+	value, _ := xlsx.GetCellValue(summarySheetName, "A2")
+	assert.Equal(t, "acme-nonprod (012345678901)", value)
+	value, _ = xlsx.GetCellValue("acme-prod", "I2")
+	assert.Equal(t, "CVE-2019-0002", value)
+}
+
+func TestGenerateReportsWithoutFindings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// reports are still generated, with the run info listing the accounts that failed
+	timeStamp := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	tems := targetErrorsMaps{{target: target{ID: "012345678901"}, errors: []annotatedError{{desc: "failed to assume role"}}}}
+	path, err := generateSpreadsheet(nil, Spreadsheet{}, newRunInfo(AppConfig{}, nil, tems, timeStamp), dir)
+	assert.NoError(t, err)
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	assert.NotZero(t, xlsx.GetSheetIndex(runInfoSheetName))
+
+	_, err = generateJSON(nil, nil, dir, timeStamp)
+	assert.NoError(t, err)
+	_, err = generateCSV(nil, dir, timeStamp)
+	assert.NoError(t, err)
+	_, err = generateHTML(nil, dir, timeStamp)
+	assert.NoError(t, err)
+}
+
+func TestGenerateSpreadsheetCustomColumns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := Spreadsheet{Columns: []Column{{Field: "title", Header: "FINDING"}, {Field: "finding-arn"}}}
+	path, err := generateSpreadsheet(testAccountsResults(), config, runInfo{timeStamp: time.Now()}, dir)
+	assert.NoError(t, err)
+
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	value, _ := xlsx.GetCellValue("acme-prod", "A1")
+	assert.Equal(t, "FINDING", value)
+	value, _ = xlsx.GetCellValue("acme-prod", "B1")
+	assert.Equal(t, "FINDING ARN", value)
+	value, _ = xlsx.GetCellValue("acme-prod", "B2")
+	assert.Equal(t, "arn:aws:inspector:eu-west-1:012345678901:target/0-a/template/0-b/run/0-c/finding/i-0000000003", value)
+	value, _ = xlsx.GetCellValue("acme-prod", "C1")
+	assert.Empty(t, value)
+
+	_, err = generateSpreadsheet(testAccountsResults(), Spreadsheet{Columns: []Column{{Field: "unknown"}}}, runInfo{}, dir)
+	assert.Error(t, err)
+}
+
+func TestGenerateSpreadsheetFilterUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ar := testAccountsResults()
+	usage := ar.filter(filters{
+		{TitleMatch: "^CVE-2019-0001", Severity: "low"},
+		{TitleMatch: "^5.3.1", Severity: "ignore"},
+	})
+	info := runInfo{timeStamp: time.Now(), filterUsage: usage}
+	path, err := generateSpreadsheet(ar, Spreadsheet{}, info, dir)
+	assert.NoError(t, err)
+
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, filterUsageSheetName, xlsx.GetSheetName(6))
+	assert.Equal(t, runInfoSheetName, xlsx.GetSheetName(7))
+	value, _ := xlsx.GetCellValue(filterUsageSheetName, "F1")
+	assert.Equal(t, "acme-nonprod", value)
+	value, _ = xlsx.GetCellValue(filterUsageSheetName, "D2")
+	assert.Equal(t, "2", value)
+	value, _ = xlsx.GetCellValue(filterUsageSheetName, "E2")
+	assert.Equal(t, "0", value)
+	value, _ = xlsx.GetCellValue(filterUsageSheetName, "F2")
+	assert.Equal(t, "2", value)
+	value, _ = xlsx.GetCellValue(filterUsageSheetName, "D3")
+	assert.Equal(t, "0", value)
+}
+
+func TestGenerateSpreadsheetExpiredSuppressions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ar := testAccountsResults()
+	ar.filter(filters{{TitleMatch: "^CVE-2019-0002", Severity: "ignore", Expires: "2019-01-01", Owner: "alice"}})
+	path, err := generateSpreadsheet(ar, Spreadsheet{}, runInfo{timeStamp: time.Now()}, dir)
+	assert.NoError(t, err)
+
+	// findings no longer suppressed by an expired filter are flagged in a column shown by default
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	value, _ := xlsx.GetCellValue("acme-prod", "B1")
+	assert.Equal(t, "SUPPRESSION EXPIRED", value)
+	value, _ = xlsx.GetCellValue("acme-prod", "B2")
+	assert.Equal(t, "2019-01-01 (owner: alice)", value)
+}
+
+func TestGenerateSpreadsheetChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ar := testAccountsResults()
+	changes, _ := ar.compareWithState(nil, nil, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	info := runInfo{timeStamp: time.Now(), changes: &changes}
+	path, err := generateSpreadsheet(ar, Spreadsheet{}, info, dir)
+	assert.NoError(t, err)
+
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, changesSheetName, xlsx.GetSheetName(2))
+	value, _ := xlsx.GetCellValue(changesSheetName, "B2")
+	assert.Equal(t, "4", value)
+	value, _ = xlsx.GetCellValue(changesSheetName, "A7")
+	assert.Equal(t, findingStatusNew, value)
+	value, _ = xlsx.GetCellValue("acme-prod", "B1")
+	assert.Equal(t, "STATUS", value)
+	value, _ = xlsx.GetCellValue("acme-prod", "B2")
+	assert.Equal(t, findingStatusNew, value)
+}
+
+func TestGenerateSpreadsheetSLA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	info := runInfo{timeStamp: time.Date(2019, 5, 20, 0, 0, 0, 0, time.UTC)}
+	path, err := generateSpreadsheet(testAccountsResults(), Spreadsheet{SLA: SLA{High: 14}}, info, dir)
+	assert.NoError(t, err)
+
+	xlsx, err := excelize.OpenFile(path)
+	assert.NoError(t, err)
+	value, _ := xlsx.GetCellValue("acme-nonprod", "B1")
+	assert.Equal(t, "DAYS OPEN", value)
+	value, _ = xlsx.GetCellValue("acme-nonprod", "B2")
+	assert.Equal(t, "18", value)
+	value, _ = xlsx.GetCellValue("acme-nonprod", "C2")
+	assert.Equal(t, slaStatusBreached, value)
+	value, _ = xlsx.GetCellValue(summarySheetName, "A20")
+	assert.Equal(t, "SLA", value)
+}
@@ -0,0 +1,127 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const runInfoSheetName = "Run Info"
+
+// runInfo describes how a report was generated and any issues encountered collecting findings
+type runInfo struct {
+	version      string
+	timeStamp    time.Time
+	maxReportAge int
+	inspector    string
+	configPath   string
+	filters      int
+	expiring     filters
+	filterUsage  filterUsages
+	statePath    string
+	snapshot     string
+	changes      *findingChanges
+	regions      []string
+	tems         targetErrorsMaps
+}
+
+func newRunInfo(appConfig AppConfig, accountsResults accountsResults, tems targetErrorsMaps, timeStamp time.Time) (info runInfo) {
+	info.version = appConfig.Version
+	info.timeStamp = timeStamp
+	info.maxReportAge = appConfig.MaxReportAge
+	info.configPath = appConfig.ConfigPath
+	info.inspector, _ = inspectorVersion(appConfig.Inspector)
+	info.statePath = appConfig.StatePath
+	info.snapshot = appConfig.FromSnapshot
+	info.filters = len(appConfig.filters)
+	info.expiring = expiringFilters(appConfig.filters, timeStamp, appConfig.ExpiryWarningDays)
+	info.tems = tems
+	for _, tem := range tems {
+		for _, r := range tem.regions {
+			info.regions = appendUnique(info.regions, r)
+		}
+	}
+	for _, ar := range accountsResults {
+		for _, rr := range ar.regionResults {
+			info.regions = appendUnique(info.regions, rr.region)
+		}
+	}
+	sort.Strings(info.regions)
+	return info
+}
+
+func addRunInfoSheet(xlsx *excelize.File, styles spreadsheetStyles, info runInfo) {
+	sheetName := runInfoSheetName
+	_ = xlsx.NewSheet(sheetName)
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 30)
+	_ = xlsx.SetColWidth(sheetName, "B", "B", 20)
+	_ = xlsx.SetColWidth(sheetName, "C", "C", 12)
+	_ = xlsx.SetColWidth(sheetName, "D", "E", 70)
+
+	version := info.version
+	if version == "" {
+		version = "-"
+	}
+	configPath := info.configPath
+	if configPath == "" {
+		configPath = "-"
+	}
+	settings := [][]interface{}{
+		{"VERSION", version},
+		{"RUN TIME", info.timeStamp.UTC().Format(time.ANSIC) + " UTC"},
+		{"MAX REPORT AGE (DAYS)", info.maxReportAge},
+		{"CONFIG PATH", configPath},
+		{"FILTERS", info.filters},
+		{"UNUSED FILTERS", len(info.filterUsage.unused())},
+		{"EXPIRED FILTERS MATCHING", len(info.filterUsage.expiredMatches())},
+		{"ACCOUNTS", len(info.tems)},
+		{"REGIONS", strings.Join(info.regions, ", ")},
+		{"INSPECTOR", info.inspector},
+	}
+	if info.snapshot != "" {
+		settings = append(settings, []interface{}{"FROM SNAPSHOT", info.snapshot})
+	}
+	if info.changes != nil {
+		previous := "-"
+		if !info.changes.previous.IsZero() {
+			previous = info.changes.previous.UTC().Format(time.ANSIC) + " UTC"
+		}
+		settings = append(settings, []interface{}{"STATE PATH", info.statePath}, []interface{}{"PREVIOUS RUN TIME", previous})
+	}
+	row := 0
+	for _, setting := range settings {
+		row++
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &setting)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), styles.bold)
+	}
+
+	row += 2
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"ACCOUNT", "ID", "STATUS", "ISSUE", "DETAIL"})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("E%d", row), styles.header)
+	for _, tem := range info.tems {
+		if len(tem.errors) == 0 {
+			row++
+			_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{tem.target.Alias, tem.target.ID, "OK"})
+			continue
+		}
+		for _, aErr := range tem.errors {
+			row++
+			_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{tem.target.Alias, tem.target.ID, "ERROR", aErr.desc, fmt.Sprint(aErr.err)})
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("C%d", row), styles.high)
+		}
+	}
+
+	if len(info.expiring) == 0 {
+		return
+	}
+	row += 2
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"FILTER EXPIRES", "OWNER", "TICKET", "FILTER", "COMMENT"})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("E%d", row), styles.header)
+	for _, f := range info.expiring {
+		row++
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{f.Expires, f.Owner, f.Ticket, strings.Join(f.criteria(), "\r\n"), f.Comment})
+	}
+}
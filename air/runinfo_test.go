@@ -0,0 +1,38 @@
+package air
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRunInfo(t *testing.T) {
+	appConfig := AppConfig{
+		Version:      "1.0.0",
+		ConfigPath:   "s3://my-bucket/config",
+		MaxReportAge: 30,
+		filters:      filters{{TitleMatch: "^1.4.2", Severity: "ignore"}},
+	}
+	tems := targetErrorsMaps{
+		{target: target{ID: "012345678901", Alias: "acme-nonprod"}},
+		{target: target{ID: "987654321098", Alias: "acme-prod"}},
+		{
+			target: target{ID: "111111111111", Alias: "acme-dev"},
+			errors: []annotatedError{{err: errors.New("AccessDenied"), desc: "failed to get findings", region: "eu-west-2"}},
+			// regions attempted are listed even if no results were retrieved from them
+			regions: []string{"eu-west-2", "eu-west-1"},
+		},
+	}
+	timeStamp := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	info := newRunInfo(appConfig, testAccountsResults(), tems, timeStamp)
+	assert.Equal(t, "1.0.0", info.version)
+	assert.Equal(t, timeStamp, info.timeStamp)
+	assert.Equal(t, 30, info.maxReportAge)
+	assert.Equal(t, InspectorClassic, info.inspector)
+	assert.Equal(t, "s3://my-bucket/config", info.configPath)
+	assert.Equal(t, 1, info.filters)
+	assert.Equal(t, []string{"eu-west-1", "eu-west-2", "us-east-1"}, info.regions)
+	assert.Len(t, info.tems, 3)
+}
@@ -1,14 +1,11 @@
 package air
 
 import (
-	"context"
 	"fmt"
-	"log"
-	"os"
+	"sort"
+	"sync"
 	"time"
 
-	"golang.org/x/sync/errgroup"
-
 	"github.com/aws/aws-sdk-go/service/inspector/inspectoriface"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -16,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/inspector"
+	"github.com/pkg/errors"
 	"github.com/snwfdhmp/errlog"
 )
 
@@ -95,44 +93,54 @@ func getAssessmentTemplatesArns(svc inspectoriface.InspectorAPI, targetArns []*s
 type annotatedError struct {
 	err  error
 	desc string
+	// region the error occurred in, or empty if it affected the whole account
+	region string
 }
 
-func processAllRegions(creds *credentials.Credentials, inspectorRegions []string, maxReportAge int) (results []regionResult, err error) {
-	GetRegionResults := func(ctx context.Context, regions []string) ([]regionResult, error) {
-		g, ctx := errgroup.WithContext(ctx)
-
-		perRegionResults := make([]regionResult, len(regions))
-		for i, region := range regions {
-			i := i
-			region := region
-			g.Go(func() error {
-				// TODO: catch errors
-				var rtr regionResult
-				rtr.region = region
-				var sess *session.Session
-				sess, err = session.NewSession(&aws.Config{Credentials: creds, Region: &region})
-				var regionTemplateResults regionTemplateResults
-				svc := inspector.New(sess)
-				regionTemplateResults, err = getRegionTemplateResults(svc, maxReportAge)
-				rtr.regionTemplateResults = append(rtr.regionTemplateResults, regionTemplateResults...)
-				if err == nil {
-					perRegionResults[i] = rtr
-				}
-				return err
-			})
-		}
-		if err := g.Wait(); err != nil {
-			return nil, err
-		}
-		return perRegionResults, nil
+// processAllRegions retrieves findings from up to the number of regions specified by concurrency at the same time
+// a region that fails does not prevent the results of the others being returned
+func processAllRegions(creds *credentials.Credentials, inspectorRegions []string, maxReportAge, concurrency int) ([]regionResult, []annotatedError) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	perRegionResults := make([]regionResult, len(inspectorRegions))
+	perRegionErrors := make([]error, len(inspectorRegions))
+	for i, region := range inspectorRegions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			sess, err := session.NewSession(&aws.Config{Credentials: creds, Region: &region})
+			if err != nil {
+				perRegionErrors[i] = errors.WithStack(err)
+				return
+			}
+			var rtrs regionTemplateResults
+			if rtrs, err = getRegionTemplateResults(inspector.New(sess), maxReportAge); err != nil {
+				perRegionErrors[i] = err
+				return
+			}
+			perRegionResults[i] = regionResult{region: region, regionTemplateResults: rtrs}
+		}(i, region)
 	}
+	wg.Wait()
+	return collectRegionResults(inspectorRegions, perRegionResults, perRegionErrors, "Inspector Classic")
+}
 
-	results, err = GetRegionResults(context.Background(), inspectorRegions)
-	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		return
+// collectRegionResults returns the results of the regions that succeeded and an error for each that failed, in region order
+func collectRegionResults(regions []string, perRegionResults []regionResult, perRegionErrors []error, service string) (results []regionResult, errs []annotatedError) {
+	for i, region := range regions {
+		if perRegionErrors[i] != nil {
+			errs = append(errs, annotatedError{
+				err:    perRegionErrors[i],
+				desc:   fmt.Sprintf("failed to get %s findings in region: %s", service, region),
+				region: region,
+			})
+			continue
+		}
+		results = append(results, perRegionResults[i])
 	}
-	return results, err
+	return results, errs
 }
 
 func getLatestAssessmentTemplateRuns(svc inspectoriface.InspectorAPI, templateArns []*string) ([]*string, error) {
@@ -175,8 +183,7 @@ func getAssessmentRunDetails(svc inspectoriface.InspectorAPI, assessmentRunArns
 		var dardo *inspector.DescribeAssessmentRunsOutput
 		dardo, err = svc.DescribeAssessmentRuns(dardi)
 		if err != nil {
-			log.Fatal(err)
-			return assessmentRunDetails, err
+			return assessmentRunDetails, errors.WithStack(err)
 		}
 		assessmentRunDetails = append(assessmentRunDetails, dardo.AssessmentRuns...)
 	}
@@ -217,6 +224,9 @@ func getRegionTemplateResults(svc inspectoriface.InspectorAPI, maxReportAge int)
 	// Output templates
 	var assTemplatesArns []*string
 	assTemplatesArns, err = getAssessmentTemplatesArns(svc, assTargetArns)
+	if err != nil {
+		return
+	}
 
 	for _, assTemplateArn := range assTemplatesArns {
 		var result regionTemplateResult
@@ -276,9 +286,9 @@ func getRegionTemplateResults(svc inspectoriface.InspectorAPI, maxReportAge int)
 		dtni := inspector.DescribeAssessmentTemplatesInput{
 			AssessmentTemplateArns: aTa,
 		}
-		var dtno *inspector.DescribeAssessmentTemplatesOutput
-		dtno, err = svc.DescribeAssessmentTemplates(&dtni)
-		if dtno != nil {
+		// a missing name is not worth losing the findings for
+		dtno, dtErr := svc.DescribeAssessmentTemplates(&dtni)
+		if dtErr == nil && dtno != nil && len(dtno.AssessmentTemplates) > 0 {
 			result.templateName = *dtno.AssessmentTemplates[0].Name
 		} else {
 			result.templateName = "-"
@@ -290,11 +300,14 @@ func getRegionTemplateResults(svc inspectoriface.InspectorAPI, maxReportAge int)
 			var findingArns []*string
 			findingArns, err = listFindingArns(svc, runArn)
 			if err != nil {
-				log.Fatal(err)
+				return
 			}
 
 			var findings findings
 			findings, err = describeFindings(svc, findingArns)
+			if err != nil {
+				return
+			}
 			resultRun.findings = append(resultRun.findings, findings...)
 			result.templateArn = *assTemplateArn
 
@@ -370,7 +383,7 @@ func getRulesPackages(svc inspectoriface.InspectorAPI) (map[string]string, error
 func describeFindings(svc inspectoriface.InspectorAPI, findingsArns []*string) (findings, error) {
 	var err error
 	var results findings
-	for i := 0; i <= len(findingsArns); i += 100 {
+	for i := 0; i < len(findingsArns); i += 100 {
 		var last int
 		if i+100 > len(findingsArns) {
 			last = len(findingsArns)
@@ -393,24 +406,16 @@ func describeFindings(svc inspectoriface.InspectorAPI, findingsArns []*string) (
 	return results, err
 }
 
-func getAllInspectorRegions() (result []string) {
-	nonStandardRegions := []string{"cn-north-1", "cn-northwest-1", "us-gov-east-1", "us-gov-west-1"}
-	resolver := endpoints.DefaultResolver()
-	partitions := resolver.(endpoints.EnumPartitions).Partitions()
+func getAllInspectorRegions(partition string) []string {
+	return getServiceRegions(partition, "inspector")
+}
 
-	resMaps := make([]map[string]endpoints.Region, 0, len(partitions))
-	for _, p := range partitions {
-		resMap, _ := endpoints.RegionsForService(endpoints.DefaultPartitions(), p.ID(), "inspector")
-		resMaps = append(resMaps, resMap)
-	}
-	keys := make([]string, 0, len(resMaps))
-	for _, resMap := range resMaps {
-		for _, ra := range resMap {
-			if !stringInSlice(ra.ID(), nonStandardRegions) {
-				keys = append(keys, ra.ID())
-			}
-		}
+// getServiceRegions returns the regions of the partition in which the service is available
+func getServiceRegions(partition, service string) (result []string) {
+	regions, _ := endpoints.RegionsForService(endpoints.DefaultPartitions(), partition, service)
+	for id := range regions {
+		result = append(result, id)
 	}
-	result = keys
+	sort.Strings(result)
 	return
 }
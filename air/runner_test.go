@@ -1,6 +1,7 @@
 package air
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -42,8 +43,21 @@ func TestGetRegionTemplateResultsComplete(t *testing.T) {
 }
 
 func TestGetAllInspectorRegionsComplete(t *testing.T) {
-	results := getAllInspectorRegions()
+	results := getAllInspectorRegions(PartitionAWS)
 	assert.NotEmpty(t, results)
 	assert.Contains(t, results, "eu-west-1")
-	assert.Len(t, results, 10)
+	assert.Len(t, results, 12)
+	assert.Equal(t, []string{"us-gov-east-1", "us-gov-west-1"}, getAllInspectorRegions(PartitionGovCloud))
+}
+
+func TestCollectRegionResults(t *testing.T) {
+	regions := []string{"eu-west-1", "eu-west-2", "eu-west-3"}
+	perRegionResults := []regionResult{{region: "eu-west-1"}, {}, {region: "eu-west-3"}}
+	perRegionErrors := []error{nil, errors.New("AccessDeniedException"), nil}
+	results, errs := collectRegionResults(regions, perRegionResults, perRegionErrors, "Inspector Classic")
+	assert.Len(t, results, 2)
+	assert.Equal(t, "eu-west-3", results[1].region)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "eu-west-2", errs[0].region)
+	assert.Equal(t, "failed to get Inspector Classic findings in region: eu-west-2", errs[0].desc)
 }
@@ -0,0 +1,111 @@
+package air
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	slaStatusBreached = "BREACHED"
+	slaStatusDueSoon  = "DUE SOON"
+	slaStatusWithin   = "WITHIN SLA"
+
+	defaultSLAWarningDays = 7
+)
+
+// SLA defines the number of days findings of each severity can remain open before being in breach
+// severities without a number of days have no SLA
+type SLA struct {
+	Critical      int `yaml:"critical"`
+	High          int `yaml:"high"`
+	Medium        int `yaml:"medium"`
+	Low           int `yaml:"low"`
+	Informational int `yaml:"informational"`
+	// number of days before breaching to mark a finding as due soon, defaulting to 7
+	WarningDays int `yaml:"warningDays"`
+}
+
+func (s SLA) defined() bool {
+	return s.Critical > 0 || s.High > 0 || s.Medium > 0 || s.Low > 0 || s.Informational > 0
+}
+
+// check returns an error if any number of days is negative
+func (s SLA) check() error {
+	for _, v := range []struct {
+		key  string
+		days int
+	}{
+		{"critical", s.Critical},
+		{"high", s.High},
+		{"medium", s.Medium},
+		{"low", s.Low},
+		{"informational", s.Informational},
+		{"warningDays", s.WarningDays},
+	} {
+		if v.days < 0 {
+			return fmt.Errorf("sla %s must not be negative", v.key)
+		}
+	}
+	return nil
+}
+
+func (s SLA) days(severity string) int {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL":
+		return s.Critical
+	case "HIGH":
+		return s.High
+	case "MEDIUM":
+		return s.Medium
+	case "LOW":
+		return s.Low
+	case "INFORMATIONAL":
+		return s.Informational
+	}
+	return 0
+}
+
+// status returns whether a finding open for the number of days is within its SLA, due soon, or in breach
+// an empty string is returned if the severity has no SLA
+func (s SLA) status(severity string, daysOpen int) string {
+	days := s.days(severity)
+	if days == 0 {
+		return ""
+	}
+	warningDays := s.WarningDays
+	if warningDays == 0 {
+		warningDays = defaultSLAWarningDays
+	}
+	switch {
+	case daysOpen > days:
+		return slaStatusBreached
+	case daysOpen > days-warningDays:
+		return slaStatusDueSoon
+	}
+	return slaStatusWithin
+}
+
+// openFor returns the number of whole days since the finding was first seen
+// if the first seen date is unknown, because previous runs are not being tracked, the date the finding was created is used
+func (dr dataRow) openFor(now time.Time) int {
+	opened := dr.firstSeen
+	if opened.IsZero() {
+		opened = dr.createdAt
+	}
+	if opened.IsZero() || now.Before(opened) {
+		return 0
+	}
+	return int(now.Sub(opened).Hours() / 24)
+}
+
+// applySLA sets the number of days each finding has been open and its SLA status
+func applySLA(data []accountSpreadsheetData, sla SLA, now time.Time) {
+	for i := range data {
+		for j := range data[i].rows {
+			dr := &data[i].rows[j]
+			dr.daysOpen = dr.openFor(now)
+			dr.slaStatus = sla.status(dr.severity, dr.daysOpen)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package air
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLAStatus(t *testing.T) {
+	sla := SLA{High: 14, Medium: 30}
+	assert.True(t, sla.defined())
+	assert.False(t, SLA{WarningDays: 3}.defined())
+
+	assert.Equal(t, slaStatusWithin, sla.status("HIGH", 7))
+	assert.Equal(t, slaStatusDueSoon, sla.status("HIGH", 8))
+	assert.Equal(t, slaStatusDueSoon, sla.status("high", 14))
+	assert.Equal(t, slaStatusBreached, sla.status("HIGH", 15))
+	assert.Equal(t, slaStatusWithin, sla.status("MEDIUM", 23))
+	assert.Empty(t, sla.status("LOW", 365))
+	assert.Empty(t, sla.status("IGNORE", 365))
+
+	sla.WarningDays = 2
+	assert.Equal(t, slaStatusWithin, sla.status("HIGH", 12))
+	assert.Equal(t, slaStatusDueSoon, sla.status("HIGH", 13))
+
+	assert.NoError(t, sla.check())
+	assert.EqualError(t, SLA{Critical: 7, Low: -1}.check(), "sla low must not be negative")
+}
+
+func TestApplySLA(t *testing.T) {
+	now := time.Date(2019, 5, 20, 12, 0, 0, 0, time.UTC)
+	ar := testAccountsResults()
+	// first seen dates from previous runs take precedence over the date the finding was created
+	ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[1].firstSeen = time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	data := generateSpreadsheetData(ar)
+	applySLA(data, SLA{High: 14, Low: 30}, now)
+
+	rows := data[0].rows
+	assert.Equal(t, 19, rows[0].daysOpen)
+	assert.Equal(t, slaStatusBreached, rows[0].slaStatus)
+	assert.Equal(t, 49, rows[1].daysOpen)
+	assert.Equal(t, slaStatusBreached, rows[1].slaStatus)
+	assert.Empty(t, rows[2].slaStatus)
+	assert.Equal(t, slaStatusWithin, data[1].rows[0].slaStatus)
+}
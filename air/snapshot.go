@@ -0,0 +1,222 @@
+package air
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/inspector"
+	"github.com/pkg/errors"
+)
+
+// snapshotSchemaVersion is incremented whenever the structure of the JSON report changes
+// see docs/json.md for the schema
+const snapshotSchemaVersion = 3
+
+type snapshot struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	GeneratedAt   time.Time         `json:"generatedAt"`
+	Accounts      []snapshotAccount `json:"accounts"`
+	// errors encountered retrieving findings, so the findings of failed accounts and regions aren't treated as resolved
+	Errors []snapshotError `json:"errors,omitempty"`
+}
+
+type snapshotError struct {
+	AccountID    string `json:"accountId"`
+	AccountAlias string `json:"accountAlias"`
+	// empty if the error prevented findings being retrieved from any region in the account
+	Region      string `json:"region,omitempty"`
+	Description string `json:"description"`
+	Error       string `json:"error"`
+}
+
+type snapshotAccount struct {
+	ID      string           `json:"id"`
+	Alias   string           `json:"alias"`
+	Regions []snapshotRegion `json:"regions"`
+}
+
+type snapshotRegion struct {
+	Region    string             `json:"region"`
+	Templates []snapshotTemplate `json:"templates"`
+}
+
+type snapshotTemplate struct {
+	Arn  string        `json:"arn"`
+	Name string        `json:"name"`
+	Runs []snapshotRun `json:"runs"`
+}
+
+type snapshotRun struct {
+	Arn      string            `json:"arn"`
+	Findings []snapshotFinding `json:"findings"`
+}
+
+type snapshotFinding struct {
+	RulesPackageName string            `json:"rulesPackageName"`
+	Comment          string            `json:"comment,omitempty"`
+	OriginalSeverity string            `json:"originalSeverity,omitempty"`
+	Finding          inspector.Finding `json:"finding"`
+}
+
+func newSnapshot(accountsResults accountsResults, tems targetErrorsMaps, generatedAt time.Time) (s snapshot) {
+	s.SchemaVersion = snapshotSchemaVersion
+	s.GeneratedAt = generatedAt
+	s.Accounts = make([]snapshotAccount, 0, len(accountsResults))
+	for _, ar := range accountsResults {
+		sa := snapshotAccount{
+			ID:      ar.accountID,
+			Alias:   ar.accountAlias,
+			Regions: make([]snapshotRegion, 0, len(ar.regionResults)),
+		}
+		for _, rr := range ar.regionResults {
+			sr := snapshotRegion{
+				Region:    rr.region,
+				Templates: make([]snapshotTemplate, 0, len(rr.regionTemplateResults)),
+			}
+			for _, rtr := range rr.regionTemplateResults {
+				st := snapshotTemplate{
+					Arn:  rtr.templateArn,
+					Name: rtr.templateName,
+					Runs: make([]snapshotRun, 0, len(rtr.runs)),
+				}
+				for _, r := range rtr.runs {
+					sRun := snapshotRun{
+						Arn:      r.runArn,
+						Findings: make([]snapshotFinding, 0, len(r.findings)),
+					}
+					for _, f := range r.findings {
+						sRun.Findings = append(sRun.Findings, snapshotFinding{
+							RulesPackageName: f.rulePackageName,
+							Comment:          f.comment,
+							OriginalSeverity: f.originalSeverity,
+							Finding:          f.Finding,
+						})
+					}
+					st.Runs = append(st.Runs, sRun)
+				}
+				sr.Templates = append(sr.Templates, st)
+			}
+			sa.Regions = append(sa.Regions, sr)
+		}
+		s.Accounts = append(s.Accounts, sa)
+	}
+	for _, tem := range tems {
+		for _, aErr := range tem.errors {
+			se := snapshotError{
+				AccountID:    tem.target.ID,
+				AccountAlias: tem.target.Alias,
+				Region:       aErr.region,
+				Description:  aErr.desc,
+			}
+			if aErr.err != nil {
+				se.Error = aErr.err.Error()
+			}
+			s.Errors = append(s.Errors, se)
+		}
+	}
+	return s
+}
+
+func loadSnapshot(path string) (s snapshot, err error) {
+	content, found, err := readFile(path)
+	if err != nil {
+		return s, err
+	}
+	if !found {
+		return s, fmt.Errorf("snapshot %s not found", path)
+	}
+	if err = json.Unmarshal(content, &s); err != nil {
+		return s, errors.Wrapf(err, "failed to parse snapshot %s", path)
+	}
+	if s.SchemaVersion == 0 || s.SchemaVersion > snapshotSchemaVersion {
+		return s, fmt.Errorf("snapshot %s has unsupported schema version %d", path, s.SchemaVersion)
+	}
+	return s, nil
+}
+
+// removeFilters restores the severity reported by Inspector for each finding changed by a filter so filters can be reapplied
+// snapshots prior to schema version 2 do not record the original severity, so are left unchanged
+func (s *snapshot) removeFilters() {
+	if s.SchemaVersion < 2 {
+		return
+	}
+	for _, sa := range s.Accounts {
+		for _, sr := range sa.Regions {
+			for _, st := range sr.Templates {
+				for _, sRun := range st.Runs {
+					for i := range sRun.Findings {
+						sf := &sRun.Findings[i]
+						// comments are only set by filters
+						sf.Comment = ""
+						if sf.OriginalSeverity != "" {
+							sf.Finding.Severity = ptrToStr(sf.OriginalSeverity)
+							sf.OriginalSeverity = ""
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// accountsResults converts the snapshot back into the results it was generated from
+func (s snapshot) accountsResults() (out accountsResults) {
+	for _, sa := range s.Accounts {
+		ar := accountResults{accountID: sa.ID, accountAlias: sa.Alias}
+		for _, sr := range sa.Regions {
+			rr := regionResult{region: sr.Region}
+			for _, st := range sr.Templates {
+				rtr := regionTemplateResult{templateArn: st.Arn, templateName: st.Name}
+				for _, sRun := range st.Runs {
+					r := run{runArn: sRun.Arn}
+					for _, sf := range sRun.Findings {
+						r.findings = append(r.findings, finding{
+							Finding:          sf.Finding,
+							rulePackageName:  sf.RulesPackageName,
+							comment:          sf.Comment,
+							originalSeverity: sf.OriginalSeverity,
+						})
+					}
+					rtr.runs = append(rtr.runs, r)
+				}
+				rr.regionTemplateResults = append(rr.regionTemplateResults, rtr)
+			}
+			ar.regionResults = append(ar.regionResults, rr)
+		}
+		out = append(out, ar)
+	}
+	return out
+}
+
+// targetErrorsMaps returns the errors recorded in the snapshot by target
+// snapshots prior to schema version 3 do not record errors
+func (s snapshot) targetErrorsMaps() (tems targetErrorsMaps) {
+	index := make(map[string]int)
+	for _, se := range s.Errors {
+		i, ok := index[se.AccountID]
+		if !ok {
+			i = len(tems)
+			index[se.AccountID] = i
+			tems = append(tems, targetErrorsMap{target: target{ID: se.AccountID, Alias: se.AccountAlias}})
+		}
+		tems[i].errors = append(tems[i].errors, annotatedError{err: errors.New(se.Error), desc: se.Description, region: se.Region})
+	}
+	return tems
+}
+
+func generateJSON(accountsResults accountsResults, tems targetErrorsMaps, outputDir string, timeStamp time.Time) (string, error) {
+	content, err := json.MarshalIndent(newSnapshot(accountsResults, tems, timeStamp), "", "  ")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	path := getReportPath(outputDir, timeStamp, FormatJSON)
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		return "", errors.WithStack(err)
+	}
+	absPath, _ := filepath.Abs(path)
+	fmt.Println("report written to:", absPath)
+	return absPath, err
+}
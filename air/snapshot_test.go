@@ -0,0 +1,227 @@
+package air
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/inspector"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFinding(title, severity, instanceID, instanceName string) finding {
+	return finding{
+		Finding: inspector.Finding{
+			Arn:            ptrToStr("arn:aws:inspector:eu-west-1:012345678901:target/0-a/template/0-b/run/0-c/finding/" + instanceID),
+			Title:          ptrToStr(title),
+			Severity:       ptrToStr(severity),
+			Description:    ptrToStr("description of " + title),
+			Recommendation: ptrToStr("recommendation for " + title),
+			CreatedAt:      ptrToTime(time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)),
+			AssetAttributes: &inspector.AssetAttributes{
+				AgentId: ptrToStr(instanceID),
+				Tags: []*inspector.Tag{
+					{Key: ptrToStr("Name"), Value: ptrToStr(instanceName)},
+				},
+			},
+			ServiceAttributes: &inspector.ServiceAttributes{
+				RulesPackageArn: ptrToStr("arn:aws:inspector:eu-west-1:357557129151:rulespackage/0-ubA5XvBh"),
+			},
+		},
+		rulePackageName: "Common Vulnerabilities and Exposures",
+	}
+}
+
+func testAccountsResults() accountsResults {
+	return accountsResults{
+		{
+			accountID:    "012345678901",
+			accountAlias: "acme-nonprod",
+			regionResults: []regionResult{
+				{
+					region: "eu-west-1",
+					regionTemplateResults: []regionTemplateResult{
+						{
+							templateArn:  "arn:aws:inspector:eu-west-1:012345678901:target/0-a/template/0-b",
+							templateName: "weekly",
+							runs: []run{
+								{
+									runArn: "arn:aws:inspector:eu-west-1:012345678901:target/0-a/template/0-b/run/0-c",
+									findings: findings{
+										testFinding("CVE-2019-0001", "High", "i-0000000001", "web"),
+										testFinding("CVE-2019-0001", "High", "i-0000000002", "web"),
+										testFinding("1.4.2 Ensure bootloader password is set", "Medium", "i-0000000001", "web"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			accountID:    "987654321098",
+			accountAlias: "acme-prod",
+			regionResults: []regionResult{
+				{
+					region: "us-east-1",
+					regionTemplateResults: []regionTemplateResult{
+						{
+							templateArn:  "arn:aws:inspector:us-east-1:987654321098:target/0-d/template/0-e",
+							templateName: "daily",
+							runs: []run{
+								{
+									runArn: "arn:aws:inspector:us-east-1:987654321098:target/0-d/template/0-e/run/0-f",
+									findings: findings{
+										testFinding("CVE-2019-0002", "Low", "i-0000000003", "api"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNewSnapshot(t *testing.T) {
+	generatedAt := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := newSnapshot(testAccountsResults(), nil, generatedAt)
+	assert.Equal(t, snapshotSchemaVersion, s.SchemaVersion)
+	assert.Equal(t, generatedAt, s.GeneratedAt)
+	assert.Len(t, s.Accounts, 2)
+	assert.Equal(t, "acme-nonprod", s.Accounts[0].Alias)
+	assert.Equal(t, "eu-west-1", s.Accounts[0].Regions[0].Region)
+	assert.Equal(t, "weekly", s.Accounts[0].Regions[0].Templates[0].Name)
+	assert.Len(t, s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings, 3)
+	sf := s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings[0]
+	assert.Equal(t, "Common Vulnerabilities and Exposures", sf.RulesPackageName)
+	assert.Equal(t, "CVE-2019-0001", *sf.Finding.Title)
+}
+
+func TestGenerateJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ar := testAccountsResults()
+	ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[2].Severity = ptrToStr("ignore")
+	ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[2].comment = "not viable in AWS"
+	path, err := generateJSON(ar, nil, dir, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "inspector_report_20190601000000.json", path[len(path)-36:])
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var s snapshot
+	assert.NoError(t, json.Unmarshal(content, &s))
+	sf := s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings[2]
+	assert.Equal(t, "ignore", *sf.Finding.Severity)
+	assert.Equal(t, "not viable in AWS", sf.Comment)
+	assert.Empty(t, s.Errors)
+
+	tems := targetErrorsMaps{{target: target{ID: "987654321098", Alias: "acme-prod"},
+		errors: []annotatedError{{err: errors.New("AccessDenied"), desc: "failed to get Inspector v2 findings in region: eu-west-2", region: "eu-west-2"}}}}
+	path, err = generateJSON(ar, tems, dir, time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	s, err = loadSnapshot(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []snapshotError{{AccountID: "987654321098", AccountAlias: "acme-prod", Region: "eu-west-2",
+		Description: "failed to get Inspector v2 findings in region: eu-west-2", Error: "AccessDenied"}}, s.Errors)
+	loaded := s.targetErrorsMaps()
+	assert.Len(t, loaded, 1)
+	assert.Equal(t, "acme-prod", loaded[0].target.Alias)
+	assert.Equal(t, "eu-west-2", loaded[0].errors[0].region)
+	assert.EqualError(t, loaded[0].errors[0].err, "AccessDenied")
+}
+
+func TestSnapshotRemoveFilters(t *testing.T) {
+	ar := testAccountsResults()
+	ar.filter(filters{{TitleMatch: "^1.4.2", Severity: "ignore", Comment: "not viable in AWS"}})
+	s := newSnapshot(ar, nil, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	sf := s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings[2]
+	assert.Equal(t, "ignore", *sf.Finding.Severity)
+	assert.Equal(t, "Medium", sf.OriginalSeverity)
+
+	s.removeFilters()
+	sf = s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings[2]
+	assert.Equal(t, "Medium", *sf.Finding.Severity)
+	assert.Empty(t, sf.OriginalSeverity)
+	assert.Empty(t, sf.Comment)
+
+	// original severities are unknown prior to schema version 2
+	s = newSnapshot(ar, nil, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	s.SchemaVersion = 1
+	s.removeFilters()
+	assert.Equal(t, "ignore", *s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings[2].Finding.Severity)
+}
+
+func TestRunFromSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// snapshot saved by a run that suppressed the bootloader finding
+	ar := testAccountsResults()
+	ar.filter(filters{{TitleMatch: "^1.4.2", Severity: "ignore"}})
+	snapshotPath, err := generateJSON(ar, nil, dir, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	// filters have since changed to suppress the CVEs instead
+	configPath := filepath.Join(dir, "config")
+	assert.NoError(t, os.Mkdir(configPath, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(configPath, filtersFileName), []byte("- title-match: ^CVE\n  severity: ignore\n"), 0644))
+	outputDir := filepath.Join(dir, "output")
+	assert.NoError(t, os.Mkdir(outputDir, 0755))
+
+	err = Run(AppConfig{
+		ConfigPath:   configPath,
+		OutputDir:    outputDir,
+		Formats:      []string{FormatJSON},
+		FromSnapshot: snapshotPath,
+		FailOn:       "medium",
+	})
+	assert.Equal(t, SeverityThresholdError{Severity: "MEDIUM", Findings: 1}, err)
+
+	paths, err := filepath.Glob(filepath.Join(outputDir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, paths, 1)
+	s, err := loadSnapshot(paths[0])
+	assert.NoError(t, err)
+	findings := s.Accounts[0].Regions[0].Templates[0].Runs[0].Findings
+	assert.Equal(t, "ignore", *findings[0].Finding.Severity)
+	assert.Equal(t, "High", findings[0].OriginalSeverity)
+	assert.Equal(t, "Medium", *findings[2].Finding.Severity)
+
+	_, err = loadSnapshot(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestLoadSnapshotResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// acme-prod failed in one region and a third account failed entirely
+	ar := testAccountsResults()
+	tems := targetErrorsMaps{
+		{target: target{ID: "987654321098", Alias: "acme-prod"}, errors: []annotatedError{{err: errors.New("AccessDenied"), desc: "failed", region: "eu-west-2"}}},
+		{target: target{ID: "111111111111", Alias: "acme-dev"}, errors: []annotatedError{{err: errors.New("AccessDenied"), desc: "failed to assume role"}}},
+	}
+	path, err := generateJSON(ar, tems, dir, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	loaded, loadedTems, err := loadSnapshotResults(path)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, 2)
+	assert.Len(t, loadedTems, 3)
+	assert.Empty(t, loadedTems[0].errors)
+	assert.Equal(t, "eu-west-2", loadedTems[1].errors[0].region)
+	assert.Equal(t, "acme-dev", loadedTems[2].target.Alias)
+	assert.Equal(t, "failed to assume role", loadedTems[2].errors[0].desc)
+}
@@ -0,0 +1,207 @@
+package air
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// stateSchemaVersion is incremented whenever the structure of the state file changes
+	stateSchemaVersion = 1
+
+	findingStatusNew        = "NEW"
+	findingStatusPersisting = "PERSISTING"
+	findingStatusResolved   = "RESOLVED"
+)
+
+// findingsState is persisted after each run so the findings of the next run can be compared against it
+type findingsState struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	GeneratedAt   time.Time      `json:"generatedAt"`
+	Findings      []stateFinding `json:"findings"`
+}
+
+// stateFinding is the normalised form of a finding used to identify it across runs
+type stateFinding struct {
+	AccountID    string    `json:"accountId"`
+	AccountAlias string    `json:"accountAlias"`
+	Region       string    `json:"region"`
+	AgentID      string    `json:"agentId"`
+	InstanceName string    `json:"instanceName"`
+	RulesPackage string    `json:"rulesPackage"`
+	Title        string    `json:"title"`
+	Severity     string    `json:"severity"`
+	FirstSeen    time.Time `json:"firstSeen"`
+}
+
+func (sf stateFinding) key() string {
+	return strings.Join([]string{sf.AccountID, sf.Region, sf.AgentID, sf.RulesPackage, sf.Title}, "|")
+}
+
+func newStateFinding(ar accountResults, region string, f finding) stateFinding {
+	sf := stateFinding{
+		AccountID:    ar.accountID,
+		AccountAlias: ar.accountAlias,
+		Region:       region,
+		InstanceName: getInstanceName(f),
+		RulesPackage: f.rulePackageName,
+		Title:        derefStr(f.Title),
+		Severity:     strings.ToUpper(derefStr(f.Severity)),
+	}
+	if f.AssetAttributes != nil {
+		sf.AgentID = derefStr(f.AssetAttributes.AgentId)
+	}
+	return sf
+}
+
+// findingChanges summarises the differences between the findings of this run and the previous one
+// new and resolved exclude findings with a severity of IGNORE
+type findingChanges struct {
+	// time of the previous run, which is zero if no previous state was found
+	previous   time.Time
+	new        []stateFinding
+	persisting int
+	resolved   []stateFinding
+}
+
+func loadFindingsState(path string) (state *findingsState, err error) {
+	content, found, err := readFile(path)
+	if err != nil || !found {
+		return nil, err
+	}
+	state = &findingsState{}
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse state file %s", path)
+	}
+	return state, nil
+}
+
+func saveFindingsState(path string, state findingsState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return writeFile(path, content)
+}
+
+// collectionFailures are the regions, by account id, that findings could not be retrieved from
+// an account without any regions listed could not be retrieved from at all
+type collectionFailures map[string][]string
+
+func (cf collectionFailures) failed(accountID, region string) bool {
+	regions, ok := cf[accountID]
+	return ok && (len(regions) == 0 || stringInSlice(region, regions))
+}
+
+// collectionFailures returns the accounts and regions that errors were encountered retrieving findings from
+func (tems targetErrorsMaps) collectionFailures() collectionFailures {
+	cf := make(collectionFailures)
+	for _, tem := range tems {
+		for _, aErr := range tem.errors {
+			regions, ok := cf[tem.target.ID]
+			switch {
+			case aErr.region == "":
+				cf[tem.target.ID] = []string{}
+			case !ok || len(regions) > 0:
+				cf[tem.target.ID] = append(regions, aErr.region)
+			}
+		}
+	}
+	return cf
+}
+
+// compareWithState sets the status and first seen date of each finding by comparing with the previous state
+// the findings of accounts and regions that could not be retrieved are carried over rather than resolved
+func (ar *accountsResults) compareWithState(previous *findingsState, failures collectionFailures, now time.Time) (changes findingChanges, current findingsState) {
+	current = findingsState{SchemaVersion: stateSchemaVersion, GeneratedAt: now, Findings: []stateFinding{}}
+	previousFindings := make(map[string]stateFinding)
+	if previous != nil {
+		changes.previous = previous.GeneratedAt
+		for _, sf := range previous.Findings {
+			previousFindings[sf.key()] = sf
+		}
+	}
+	seen := make(map[string]bool)
+	for i := range *ar {
+		accountResults := (*ar)[i]
+		for _, rr := range accountResults.regionResults {
+			for _, rtr := range rr.regionTemplateResults {
+				for _, r := range rtr.runs {
+					for j := range r.findings {
+						f := &r.findings[j]
+						sf := newStateFinding(accountResults, rr.region, *f)
+						key := sf.key()
+						sf.FirstSeen = now
+						f.status = findingStatusNew
+						if prev, ok := previousFindings[key]; ok {
+							sf.FirstSeen = prev.FirstSeen
+							f.status = findingStatusPersisting
+						}
+						f.firstSeen = sf.FirstSeen
+						if seen[key] {
+							continue
+						}
+						seen[key] = true
+						current.Findings = append(current.Findings, sf)
+						switch {
+						case f.status == findingStatusPersisting:
+							changes.persisting++
+						case sf.Severity != "IGNORE":
+							changes.new = append(changes.new, sf)
+						}
+					}
+				}
+			}
+		}
+	}
+	if previous == nil {
+		return changes, current
+	}
+	for _, sf := range previous.Findings {
+		if seen[sf.key()] {
+			continue
+		}
+		if failures.failed(sf.AccountID, sf.Region) {
+			current.Findings = append(current.Findings, sf)
+			continue
+		}
+		if sf.Severity != "IGNORE" {
+			changes.resolved = append(changes.resolved, sf)
+		}
+	}
+	sortStateFindings(changes.new)
+	sortStateFindings(changes.resolved)
+	return changes, current
+}
+
+// sortStateFindings orders findings by severity, then account, region, instance and title
+func sortStateFindings(sfs []stateFinding) {
+	sort.SliceStable(sfs, func(i, j int) bool {
+		if severityRank[sfs[i].Severity] != severityRank[sfs[j].Severity] {
+			return severityRank[sfs[i].Severity] > severityRank[sfs[j].Severity]
+		}
+		return sfs[i].key() < sfs[j].key()
+	})
+}
+
+// compareFindingsState compares the findings with those of the previous run, stored at the path
+// the current state is returned rather than saved, so it is only saved once the reports have been delivered
+func compareFindingsState(path string, ar *accountsResults, tems targetErrorsMaps, now time.Time) (*findingChanges, findingsState, error) {
+	previous, err := loadFindingsState(path)
+	if err != nil {
+		return nil, findingsState{}, err
+	}
+	changes, current := ar.compareWithState(previous, tems.collectionFailures(), now)
+	if previous == nil {
+		fmt.Printf("no previous state found at %s, all findings are new\n", path)
+	} else {
+		fmt.Printf("changes since %s: %d new, %d persisting, %d resolved\n",
+			previous.GeneratedAt.UTC().Format(time.ANSIC), len(changes.new), changes.persisting, len(changes.resolved))
+	}
+	return &changes, current, nil
+}
@@ -0,0 +1,108 @@
+package air
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareWithState(t *testing.T) {
+	firstRun := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	ar := testAccountsResults()
+	changes, state := ar.compareWithState(nil, nil, firstRun)
+	assert.True(t, changes.previous.IsZero())
+	assert.Len(t, changes.new, 4)
+	assert.Len(t, state.Findings, 4)
+	assert.Equal(t, findingStatusNew, ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[0].status)
+
+	// one finding resolved in acme-nonprod and one new finding in acme-prod
+	secondRun := firstRun.AddDate(0, 0, 7)
+	ar = testAccountsResults()
+	ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings = ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[:2]
+	ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings = append(ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings,
+		testFinding("CVE-2019-0003", "High", "i-0000000003", "api"))
+	changes, state = ar.compareWithState(&state, nil, secondRun)
+	assert.Equal(t, firstRun, changes.previous)
+	assert.Equal(t, 3, changes.persisting)
+	assert.Len(t, changes.new, 1)
+	assert.Equal(t, "CVE-2019-0003", changes.new[0].Title)
+	assert.Len(t, changes.resolved, 1)
+	assert.Equal(t, "1.4.2 Ensure bootloader password is set", changes.resolved[0].Title)
+	assert.Len(t, state.Findings, 4)
+
+	persisting := ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[0]
+	assert.Equal(t, findingStatusPersisting, persisting.status)
+	assert.Equal(t, firstRun, persisting.firstSeen)
+	added := ar[1].regionResults[0].regionTemplateResults[0].runs[0].findings[1]
+	assert.Equal(t, findingStatusNew, added.status)
+	assert.Equal(t, secondRun, added.firstSeen)
+
+	// findings of accounts that could not be retrieved are carried over rather than resolved
+	var none accountsResults
+	changes, state = none.compareWithState(&state, collectionFailures{"987654321098": nil}, secondRun.AddDate(0, 0, 7))
+	assert.Len(t, changes.resolved, 2)
+	assert.Len(t, state.Findings, 2)
+
+	// only the findings of the regions that could not be retrieved are carried over
+	changes, state = none.compareWithState(&state, collectionFailures{"987654321098": {"eu-west-1"}}, secondRun.AddDate(0, 0, 14))
+	assert.Len(t, changes.resolved, 2)
+	assert.Len(t, state.Findings, 0)
+}
+
+func TestCollectionFailures(t *testing.T) {
+	tems := targetErrorsMaps{
+		{target: target{ID: "012345678901"}},
+		{target: target{ID: "987654321098"}, errors: []annotatedError{
+			{err: errors.New("AccessDenied"), region: "eu-west-1"},
+			{err: errors.New("AccessDenied"), region: "eu-west-2"},
+		}},
+		{target: target{ID: "111111111111"}, errors: []annotatedError{
+			{err: errors.New("AccessDenied"), region: "eu-west-1"},
+			{err: errors.New("ExpiredToken")},
+		}},
+	}
+	cf := tems.collectionFailures()
+	assert.Len(t, cf, 2)
+	assert.False(t, cf.failed("012345678901", "eu-west-1"))
+	assert.True(t, cf.failed("987654321098", "eu-west-2"))
+	assert.False(t, cf.failed("987654321098", "us-east-1"))
+	assert.True(t, cf.failed("111111111111", "us-east-1"))
+}
+
+func TestCompareFindingsState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+	ar := testAccountsResults()
+	changes, current, err := compareFindingsState(path, &ar, nil, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, changes.new, 4)
+	// nothing is saved until the reports are delivered
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, saveFindingsState(path, current))
+
+	ar = accountsResults{}
+	tems := targetErrorsMaps{{target: target{ID: "987654321098"}, errors: []annotatedError{{err: errors.New("AccessDenied")}}}}
+	changes, current, err = compareFindingsState(path, &ar, tems, time.Date(2019, 6, 8, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, changes.resolved, 3)
+	assert.NoError(t, saveFindingsState(path, current))
+
+	state, err := loadFindingsState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, stateSchemaVersion, state.SchemaVersion)
+	assert.Len(t, state.Findings, 1)
+	assert.Equal(t, "CVE-2019-0002", state.Findings[0].Title)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = loadFindingsState(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,259 @@
+package air
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const (
+	summarySheetName = "Summary"
+
+	// maximum number of finding titles listed in the summary
+	summaryTopTitles = 10
+)
+
+// summaryCount holds the number of findings, per report severity, for a single item such as an account or region
+type summaryCount struct {
+	name   string
+	counts []int
+	total  int
+}
+
+func (sc *summaryCount) add(severity string) {
+	for i, s := range reportSeverities {
+		if s == severity {
+			sc.counts[i]++
+		}
+	}
+	sc.total++
+}
+
+type titleCount struct {
+	title    string
+	severity string
+	count    int
+}
+
+// slaCount holds the number of findings in breach of, or due to breach, their SLA for an account
+type slaCount struct {
+	name     string
+	breached int
+	dueSoon  int
+}
+
+type summary struct {
+	accounts      []summaryCount
+	regions       []summaryCount
+	rulesPackages []summaryCount
+	titles        []titleCount
+	// only set if SLAs are defined
+	sla []slaCount
+}
+
+func newSummaryCount(name string) *summaryCount {
+	return &summaryCount{name: name, counts: make([]int, len(reportSeverities))}
+}
+
+// sortedSummaryCounts returns the counts ordered by name
+func sortedSummaryCounts(in map[string]*summaryCount) (out []summaryCount) {
+	for _, sc := range in {
+		out = append(out, *sc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+func generateSummary(data []accountSpreadsheetData) (s summary) {
+	regions := make(map[string]*summaryCount)
+	rulesPackages := make(map[string]*summaryCount)
+	titles := make(map[string]*titleCount)
+	var slaDefined bool
+	for _, accountData := range data {
+		account := newSummaryCount(fmt.Sprintf("%s (%s)", accountData.accountAlias, accountData.accountID))
+		if accountData.accountAlias == "" {
+			account.name = accountData.accountID
+		}
+		accountSLA := slaCount{name: account.name}
+		for _, dr := range accountData.rows {
+			account.add(dr.severity)
+			switch dr.slaStatus {
+			case slaStatusBreached:
+				accountSLA.breached++
+			case slaStatusDueSoon:
+				accountSLA.dueSoon++
+			}
+			if dr.slaStatus != "" {
+				slaDefined = true
+			}
+			if regions[dr.region] == nil {
+				regions[dr.region] = newSummaryCount(dr.region)
+			}
+			regions[dr.region].add(dr.severity)
+			if rulesPackages[dr.packageName] == nil {
+				rulesPackages[dr.packageName] = newSummaryCount(dr.packageName)
+			}
+			rulesPackages[dr.packageName].add(dr.severity)
+			// ignored findings are not of interest when listing the most common
+			if dr.severity == "IGNORE" {
+				continue
+			}
+			if titles[dr.findingTitle] == nil {
+				titles[dr.findingTitle] = &titleCount{title: dr.findingTitle, severity: dr.severity}
+			}
+			titles[dr.findingTitle].count++
+			if severityRank[dr.severity] > severityRank[titles[dr.findingTitle].severity] {
+				titles[dr.findingTitle].severity = dr.severity
+			}
+		}
+		s.accounts = append(s.accounts, *account)
+		s.sla = append(s.sla, accountSLA)
+	}
+	if !slaDefined {
+		s.sla = nil
+	}
+	s.regions = sortedSummaryCounts(regions)
+	s.rulesPackages = sortedSummaryCounts(rulesPackages)
+
+	for _, tc := range titles {
+		s.titles = append(s.titles, *tc)
+	}
+	sort.Slice(s.titles, func(i, j int) bool {
+		if s.titles[i].count != s.titles[j].count {
+			return s.titles[i].count > s.titles[j].count
+		}
+		return s.titles[i].title < s.titles[j].title
+	})
+	if len(s.titles) > summaryTopTitles {
+		s.titles = s.titles[:summaryTopTitles]
+	}
+	return s
+}
+
+// addSummaryCountTable writes a table of counts per severity starting at the given row and returns the last row written
+func addSummaryCountTable(xlsx *excelize.File, styles spreadsheetStyles, heading string, counts []summaryCount, row int) int {
+	sheetName := summarySheetName
+	lastCol, _ := excelize.ColumnNumberToName(len(reportSeverities) + 2)
+	header := []interface{}{heading}
+	for _, severity := range reportSeverities {
+		header = append(header, severity)
+	}
+	header = append(header, "TOTAL")
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &header)
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastCol, row), styles.header)
+
+	totals := newSummaryCount("TOTAL")
+	for _, sc := range counts {
+		row++
+		values := []interface{}{sc.name}
+		for i, c := range sc.counts {
+			values = append(values, c)
+			totals.counts[i] += c
+		}
+		values = append(values, sc.total)
+		totals.total += sc.total
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &values)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("%s%d", lastCol, row), styles.defaultCentered)
+	}
+	row++
+	values := []interface{}{totals.name}
+	for _, c := range totals.counts {
+		values = append(values, c)
+	}
+	values = append(values, totals.total)
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &values)
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastCol, row), styles.bold)
+	return row
+}
+
+func addSummarySheet(xlsx *excelize.File, styles spreadsheetStyles, s summary) {
+	sheetName := summarySheetName
+	lastCol, _ := excelize.ColumnNumberToName(len(reportSeverities) + 2)
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 60)
+	_ = xlsx.SetColWidth(sheetName, "B", lastCol, 18)
+
+	accountsLastRow := addSummaryCountTable(xlsx, styles, "ACCOUNT", s.accounts, 1)
+	row := addSummaryCountTable(xlsx, styles, "REGION", s.regions, accountsLastRow+2)
+	rulesPackagesFirstRow := row + 2
+	row = addSummaryCountTable(xlsx, styles, "RULES PACKAGE", s.rulesPackages, rulesPackagesFirstRow)
+	if len(s.accounts) > 0 {
+		addSummaryCharts(xlsx, accountsLastRow, rulesPackagesFirstRow, row)
+	}
+
+	row += 2
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"MOST COMMON FINDINGS", "SEVERITY", "COUNT"})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), styles.header)
+	for _, tc := range s.titles {
+		row++
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{tc.title, tc.severity, tc.count})
+		if style := styles.severity(tc.severity); style != 0 {
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), style)
+		}
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("C%d", row), styles.defaultCentered)
+	}
+
+	if len(s.sla) == 0 {
+		return
+	}
+	row += 2
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"SLA", slaStatusBreached, slaStatusDueSoon})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), styles.header)
+	var breached, dueSoon int
+	for _, sc := range s.sla {
+		row++
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{sc.name, sc.breached, sc.dueSoon})
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("C%d", row), styles.defaultCentered)
+		breached += sc.breached
+		dueSoon += sc.dueSoon
+	}
+	row++
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"TOTAL", breached, dueSoon})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), styles.bold)
+}
+
+// addSummaryCharts adds charts to the summary sheet using the account and rules package tables as their source
+// the last row of each table contains the totals
+func addSummaryCharts(xlsx *excelize.File, accountsLastRow, rulesPackagesFirstRow, rulesPackagesLastRow int) {
+	sheetName := summarySheetName
+	chartCol, _ := excelize.ColumnNumberToName(len(reportSeverities) + 4)
+
+	// severity distribution per account
+	accounts := chart{Type: "barStacked", Title: chartTitle{Name: "Findings per account"}}
+	for i := range reportSeverities {
+		accounts.Series = append(accounts.Series, chartSeries{
+			Name:       chartRange(sheetName, i+2, 1, 1),
+			Categories: chartRange(sheetName, 1, 2, accountsLastRow-1),
+			Values:     chartRange(sheetName, i+2, 2, accountsLastRow-1),
+		})
+	}
+	if err := addChart(xlsx, sheetName, chartCol+"1", accounts); err != nil {
+		fmt.Println("failed to add chart:", err)
+	}
+
+	// severity distribution across all accounts
+	severities := chart{Type: "pie", Title: chartTitle{Name: "Findings per severity"}, PlotArea: chartPlotArea{ShowPercent: true}}
+	lastSeverityCol, _ := excelize.ColumnNumberToName(len(reportSeverities) + 1)
+	severities.Series = []chartSeries{{
+		Categories: fmt.Sprintf("'%s'!$B$1:$%s$1", sheetName, lastSeverityCol),
+		Values:     fmt.Sprintf("'%s'!$B$%d:$%s$%d", sheetName, accountsLastRow, lastSeverityCol, accountsLastRow),
+	}}
+	if err := addChart(xlsx, sheetName, chartCol+"20", severities); err != nil {
+		fmt.Println("failed to add chart:", err)
+	}
+
+	// findings per rules package
+	if rulesPackagesLastRow-rulesPackagesFirstRow < 2 {
+		return
+	}
+	rulesPackages := chart{Type: "pie", Title: chartTitle{Name: "Findings per rules package"}, PlotArea: chartPlotArea{ShowVal: true}}
+	rulesPackages.Series = []chartSeries{{
+		Categories: chartRange(sheetName, 1, rulesPackagesFirstRow+1, rulesPackagesLastRow-1),
+		Values:     chartRange(sheetName, len(reportSeverities)+2, rulesPackagesFirstRow+1, rulesPackagesLastRow-1),
+	}}
+	if err := addChart(xlsx, sheetName, chartCol+"39", rulesPackages); err != nil {
+		fmt.Println("failed to add chart:", err)
+	}
+}
@@ -0,0 +1,44 @@
+package air
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSummary(t *testing.T) {
+	ar := testAccountsResults()
+	ar[0].regionResults[0].regionTemplateResults[0].runs[0].findings[2].Severity = ptrToStr("ignore")
+	s := generateSummary(generateSpreadsheetData(ar))
+
+	assert.Len(t, s.accounts, 2)
+	assert.Equal(t, "acme-nonprod (012345678901)", s.accounts[0].name)
+	assert.Equal(t, []int{0, 2, 0, 0, 0, 1}, s.accounts[0].counts)
+	assert.Equal(t, 3, s.accounts[0].total)
+
+	assert.Len(t, s.regions, 2)
+	assert.Equal(t, "eu-west-1", s.regions[0].name)
+	assert.Equal(t, "us-east-1", s.regions[1].name)
+	assert.Equal(t, []int{0, 0, 0, 1, 0, 0}, s.regions[1].counts)
+
+	assert.Len(t, s.rulesPackages, 1)
+	assert.Equal(t, 4, s.rulesPackages[0].total)
+
+	// ignored findings are excluded from the most common titles
+	assert.Len(t, s.titles, 2)
+	assert.Equal(t, titleCount{title: "CVE-2019-0001", severity: "HIGH", count: 2}, s.titles[0])
+	assert.Equal(t, titleCount{title: "CVE-2019-0002", severity: "LOW", count: 1}, s.titles[1])
+	assert.Nil(t, s.sla)
+}
+
+func TestGenerateSummarySLA(t *testing.T) {
+	data := generateSpreadsheetData(testAccountsResults())
+	// findings were created 2019-05-01
+	applySLA(data, SLA{High: 14, Medium: 30}, time.Date(2019, 5, 26, 0, 0, 0, 0, time.UTC))
+	s := generateSummary(data)
+	assert.Equal(t, []slaCount{
+		{name: "acme-nonprod (012345678901)", breached: 2, dueSoon: 1},
+		{name: "acme-prod (987654321098)"},
+	}, s.sla)
+}
@@ -0,0 +1,76 @@
+package air
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+const suppressedSheetName = "Suppressed"
+
+// separateSuppressed splits the data into findings to report and findings suppressed by a filter
+// accounts without any findings of either kind are omitted from the respective result
+func separateSuppressed(data []accountSpreadsheetData) (active, suppressed []accountSpreadsheetData) {
+	for _, accountData := range data {
+		a := accountData
+		a.rows = nil
+		s := accountData
+		s.rows = nil
+		for _, dr := range accountData.rows {
+			if dr.severity == "IGNORE" {
+				s.rows = append(s.rows, dr)
+			} else {
+				a.rows = append(a.rows, dr)
+			}
+		}
+		if len(a.rows) > 0 {
+			active = append(active, a)
+		}
+		if len(s.rows) > 0 {
+			suppressed = append(suppressed, s)
+		}
+	}
+	return
+}
+
+func addSuppressedSheet(xlsx *excelize.File, styles spreadsheetStyles, suppressed []accountSpreadsheetData) {
+	sheetName := suppressedSheetName
+	_ = xlsx.NewSheet(sheetName)
+	_ = xlsx.SetSheetRow(sheetName, "A1", &[]interface{}{"ACCOUNT", "REGION", "INSTANCE ID", "INSTANCE NAME", "RULES PACKAGE", "TITLE", "FILTER", "COMMENT", "OWNER", "TICKET", "EXPIRES"})
+	_ = xlsx.SetCellStyle(sheetName, "A1", "K1", styles.header)
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 24)
+	_ = xlsx.SetColWidth(sheetName, "B", "B", 13.5)
+	_ = xlsx.SetColWidth(sheetName, "C", "C", 19)
+	_ = xlsx.SetColWidth(sheetName, "D", "D", 24)
+	_ = xlsx.SetColWidth(sheetName, "E", "E", 44)
+	_ = xlsx.SetColWidth(sheetName, "F", "G", 60)
+	_ = xlsx.SetColWidth(sheetName, "H", "H", 60)
+	_ = xlsx.SetColWidth(sheetName, "I", "J", 24)
+	_ = xlsx.SetColWidth(sheetName, "K", "K", 15)
+	row := 1
+	for _, accountData := range suppressed {
+		for _, dr := range accountData.rows {
+			row++
+			var f filter
+			if dr.matchedFilter != nil {
+				f = *dr.matchedFilter
+			}
+			_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{
+				accountData.sheetName,
+				dr.region,
+				dr.instanceID,
+				dr.instanceName,
+				dr.packageName,
+				dr.findingTitle,
+				strings.Join(f.criteria(), "\r\n"),
+				dr.comment,
+				f.Owner,
+				f.Ticket,
+				f.Expires,
+			})
+			_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("D%d", row), styles.defaultCentered)
+		}
+	}
+	_ = xlsx.AutoFilter(sheetName, "A1", fmt.Sprintf("K%d", row), "")
+}
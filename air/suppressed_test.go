@@ -0,0 +1,27 @@
+package air
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeparateSuppressed(t *testing.T) {
+	ar := testAccountsResults()
+	ar.filter(filters{
+		{TitleMatch: "^1.4.2", Severity: "ignore", Comment: "not viable in AWS"},
+		{TitleMatch: "CVE-2019-0002", Severity: "ignore", Comment: "not exploitable"},
+	})
+	active, suppressed := separateSuppressed(generateSpreadsheetData(ar))
+
+	assert.Len(t, active, 1)
+	assert.Equal(t, "acme-nonprod", active[0].sheetName)
+	assert.Len(t, active[0].rows, 2)
+
+	assert.Len(t, suppressed, 2)
+	assert.Len(t, suppressed[0].rows, 1)
+	assert.Equal(t, "IGNORE", suppressed[0].rows[0].severity)
+	assert.Equal(t, "^1.4.2", suppressed[0].rows[0].matchedFilter.TitleMatch)
+	assert.Equal(t, "not viable in AWS", suppressed[0].rows[0].comment)
+	assert.Equal(t, "acme-prod", suppressed[1].sheetName)
+}
@@ -1,23 +1,39 @@
 package air
 
-import (
-	"io/ioutil"
-	"os"
-
-	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
-)
-
 type target struct {
 	ID             string `yaml:"id"`
 	Alias          string `yaml:"alias"`
 	RoleName       string `yaml:"roleName"`
 	RoleExternalID string `yaml:"roleExternalId"`
+	// overrides the version of Inspector to retrieve findings from
+	Inspector string `yaml:"inspector"`
+	// overrides the regions to retrieve findings from
+	Regions []string `yaml:"regions"`
+	// overrides the partition the account is in
+	Partition string `yaml:"partition"`
+}
+
+// name returns the alias of the target, or its id if it has no alias
+func (t target) name() string {
+	if t.Alias != "" {
+		return t.Alias
+	}
+	return t.ID
 }
 
 type targetErrorsMap struct {
 	target target
 	errors []annotatedError
+	// regions findings were retrieved from, or attempted to be
+	regions []string
+}
+
+func (tem *targetErrorsMap) addRegions(regionLists ...[]string) {
+	for _, regions := range regionLists {
+		for _, r := range regions {
+			tem.regions = appendUnique(tem.regions, r)
+		}
+	}
 }
 
 type targetErrorsMaps []targetErrorsMap
@@ -25,30 +41,6 @@ type targetErrorsMaps []targetErrorsMap
 type targets []target
 
 func parseTargetsFileContent(content []byte) (accounts targets, err error) {
-	var accountsInstance targets
-	unmarshalErr := yaml.Unmarshal(content, &accountsInstance)
-	if unmarshalErr != nil {
-		err = errors.WithStack(unmarshalErr)
-		return
-	}
-	accounts = accountsInstance
-	return
-}
-
-func readTargets(targetsPath string) (ret targets, err error) {
-	if _, err = os.Stat(targetsPath); err == nil {
-		_, openErr := os.Open(targetsPath)
-		if openErr != nil {
-			err = errors.WithStack(openErr)
-			return
-		}
-		targetsFileContent, readErr := ioutil.ReadFile(targetsPath)
-		if readErr != nil {
-			err = errors.WithStack(readErr)
-			return
-		}
-		ret, err = parseTargetsFileContent(targetsFileContent)
-	}
-
+	err = parseConfigContent(content, &accounts)
 	return
 }
@@ -0,0 +1,370 @@
+package air
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	TrendPeriodMonth = "month"
+	TrendPeriodWeek  = "week"
+
+	trendSheetName         = "Trend"
+	trendAccountsSheetName = "Trend by Account"
+)
+
+// trendSeverities are the severities counted in the trend, which excludes suppressed findings
+var trendSeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFORMATIONAL"}
+
+type TrendConfig struct {
+	// directory or s3://bucket/prefix containing JSON reports generated by previous runs
+	SnapshotsPath string
+	Period        string
+	OutputDir     string
+}
+
+type trend struct {
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Period      string        `json:"period"`
+	Snapshots   int           `json:"snapshots"`
+	Periods     []trendPeriod `json:"periods"`
+	// mean number of days between a finding first appearing and no longer being reported, across all periods
+	MeanTimeToRemediateDays float64 `json:"meanTimeToRemediateDays"`
+}
+
+// trendPeriod holds the findings reported by the last snapshot in the period, along with the changes during it
+type trendPeriod struct {
+	Period                  string         `json:"period"`
+	SnapshotAt              time.Time      `json:"snapshotAt"`
+	Severities              map[string]int `json:"severities"`
+	Total                   int            `json:"total"`
+	Accounts                []trendAccount `json:"accounts"`
+	Opened                  int            `json:"opened"`
+	Closed                  int            `json:"closed"`
+	MeanTimeToRemediateDays float64        `json:"meanTimeToRemediateDays"`
+	remediated              []time.Duration
+}
+
+type trendAccount struct {
+	ID         string         `json:"id"`
+	Alias      string         `json:"alias"`
+	Severities map[string]int `json:"severities"`
+	Total      int            `json:"total"`
+}
+
+func (ta trendAccount) name() string {
+	if ta.Alias != "" {
+		return ta.Alias
+	}
+	return ta.ID
+}
+
+// periodOf returns the label of the month or ISO week the time falls in
+func periodOf(t time.Time, period string) string {
+	if period == TrendPeriodWeek {
+		year, week := t.UTC().ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return t.UTC().Format("2006-01")
+}
+
+func meanDays(durations []time.Duration) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return math.Round(total.Hours()/24/float64(len(durations))*10) / 10
+}
+
+// snapshotFindings returns the unsuppressed findings in the snapshot keyed by their identity
+func snapshotFindings(s snapshot) map[string]stateFinding {
+	out := make(map[string]stateFinding)
+	for _, ar := range s.accountsResults() {
+		for _, rr := range ar.regionResults {
+			for _, rtr := range rr.regionTemplateResults {
+				for _, r := range rtr.runs {
+					for _, f := range r.findings {
+						sf := newStateFinding(ar, rr.region, f)
+						if sf.Severity == "IGNORE" {
+							continue
+						}
+						out[sf.key()] = sf
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func snapshotHasAccount(s snapshot, accountID string) bool {
+	for _, sa := range s.Accounts {
+		if sa.ID == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// generateTrend compares each snapshot with the one before it to find the findings opened and closed
+// findings in the first snapshot are treated as opened at that time
+func generateTrend(snapshots []snapshot, period string, generatedAt time.Time) (t trend) {
+	t.GeneratedAt = generatedAt
+	t.Period = period
+	t.Snapshots = len(snapshots)
+	t.Periods = []trendPeriod{}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].GeneratedAt.Before(snapshots[j].GeneratedAt)
+	})
+	firstSeen := make(map[string]time.Time)
+	var previous map[string]stateFinding
+	var remediated []time.Duration
+	for _, s := range snapshots {
+		current := snapshotFindings(s)
+		// findings that couldn't be retrieved are carried over rather than closed
+		tems := s.targetErrorsMaps()
+		failures := tems.collectionFailures()
+		for key, sf := range previous {
+			if _, ok := current[key]; !ok && failures.failed(sf.AccountID, sf.Region) {
+				current[key] = sf
+			}
+		}
+		label := periodOf(s.GeneratedAt, period)
+		if len(t.Periods) == 0 || t.Periods[len(t.Periods)-1].Period != label {
+			t.Periods = append(t.Periods, trendPeriod{Period: label})
+		}
+		tp := &t.Periods[len(t.Periods)-1]
+
+		// counts are those of the latest snapshot in the period
+		tp.SnapshotAt = s.GeneratedAt
+		tp.Severities = make(map[string]int)
+		tp.Total = 0
+		tp.Accounts = nil
+		accounts := make(map[string]*trendAccount)
+		for _, sa := range s.Accounts {
+			tp.Accounts = append(tp.Accounts, trendAccount{ID: sa.ID, Alias: sa.Alias, Severities: make(map[string]int)})
+		}
+		// accounts that couldn't be retrieved from at all aren't in the snapshot, but their findings are carried over
+		for _, tem := range tems {
+			if !snapshotHasAccount(s, tem.target.ID) {
+				tp.Accounts = append(tp.Accounts, trendAccount{ID: tem.target.ID, Alias: tem.target.Alias, Severities: make(map[string]int)})
+			}
+		}
+		for i := range tp.Accounts {
+			accounts[tp.Accounts[i].ID] = &tp.Accounts[i]
+		}
+		for key, sf := range current {
+			tp.Severities[sf.Severity]++
+			tp.Total++
+			if ta, ok := accounts[sf.AccountID]; ok {
+				ta.Severities[sf.Severity]++
+				ta.Total++
+			}
+			if _, ok := previous[key]; !ok {
+				if previous != nil {
+					tp.Opened++
+				}
+				firstSeen[key] = s.GeneratedAt
+			}
+		}
+		for key := range previous {
+			if _, ok := current[key]; ok {
+				continue
+			}
+			tp.Closed++
+			d := s.GeneratedAt.Sub(firstSeen[key])
+			tp.remediated = append(tp.remediated, d)
+			remediated = append(remediated, d)
+			delete(firstSeen, key)
+		}
+		tp.MeanTimeToRemediateDays = meanDays(tp.remediated)
+		previous = current
+	}
+	t.MeanTimeToRemediateDays = meanDays(remediated)
+	return t
+}
+
+// trendAccounts returns the name of every account that appears in the trend, in order of appearance
+func trendAccounts(t trend) (ids, names []string) {
+	for _, tp := range t.Periods {
+		for _, ta := range tp.Accounts {
+			if !stringInSlice(ta.ID, ids) {
+				ids = append(ids, ta.ID)
+				names = append(names, ta.name())
+			}
+		}
+	}
+	return ids, names
+}
+
+func addTrendSheets(xlsx *excelize.File, styles spreadsheetStyles, t trend) {
+	sheetName := trendSheetName
+	header := []interface{}{"PERIOD", "SNAPSHOT"}
+	for _, severity := range trendSeverities {
+		header = append(header, severity)
+	}
+	header = append(header, "TOTAL", "OPENED", "CLOSED", "MEAN TIME TO REMEDIATE (DAYS)")
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 14)
+	_ = xlsx.SetColWidth(sheetName, "B", "B", 26)
+	_ = xlsx.SetColWidth(sheetName, "C", lastCol, 18)
+	_ = xlsx.SetColWidth(sheetName, lastCol, lastCol, 36)
+	_ = xlsx.SetSheetRow(sheetName, "A1", &header)
+	_ = xlsx.SetCellStyle(sheetName, "A1", lastCol+"1", styles.header)
+	row := 1
+	for _, tp := range t.Periods {
+		row++
+		values := []interface{}{tp.Period, tp.SnapshotAt.UTC().Format(time.ANSIC)}
+		for _, severity := range trendSeverities {
+			values = append(values, tp.Severities[severity])
+		}
+		values = append(values, tp.Total, tp.Opened, tp.Closed, tp.MeanTimeToRemediateDays)
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &values)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("%s%d", lastCol, row), styles.defaultCentered)
+	}
+	lastRow := row
+	row += 2
+	_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &[]interface{}{"MEAN TIME TO REMEDIATE (DAYS)", nil, t.MeanTimeToRemediateDays})
+	_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), styles.bold)
+
+	severities := chart{Type: "line", Title: chartTitle{Name: "Findings per severity"}}
+	for i := range trendSeverities {
+		severities.Series = append(severities.Series, chartSeries{
+			Name:       chartRange(sheetName, i+3, 1, 1),
+			Categories: chartRange(sheetName, 1, 2, lastRow),
+			Values:     chartRange(sheetName, i+3, 2, lastRow),
+		})
+	}
+	chartCol, _ := excelize.ColumnNumberToName(len(header) + 2)
+	if err := addChart(xlsx, sheetName, chartCol+"1", severities); err != nil {
+		fmt.Println("failed to add chart:", err)
+	}
+
+	// total findings per account
+	sheetName = trendAccountsSheetName
+	_ = xlsx.NewSheet(sheetName)
+	ids, names := trendAccounts(t)
+	header = []interface{}{"PERIOD"}
+	for _, name := range names {
+		header = append(header, name)
+	}
+	lastCol, _ = excelize.ColumnNumberToName(len(header))
+	_ = xlsx.SetColWidth(sheetName, "A", "A", 14)
+	_ = xlsx.SetColWidth(sheetName, "B", lastCol, 24)
+	_ = xlsx.SetSheetRow(sheetName, "A1", &header)
+	_ = xlsx.SetCellStyle(sheetName, "A1", lastCol+"1", styles.header)
+	for i, tp := range t.Periods {
+		values := []interface{}{tp.Period}
+		for _, id := range ids {
+			var total interface{}
+			for _, ta := range tp.Accounts {
+				if ta.ID == id {
+					total = ta.Total
+				}
+			}
+			values = append(values, total)
+		}
+		_ = xlsx.SetSheetRow(sheetName, fmt.Sprintf("A%d", i+2), &values)
+		_ = xlsx.SetCellStyle(sheetName, fmt.Sprintf("B%d", i+2), fmt.Sprintf("%s%d", lastCol, i+2), styles.defaultCentered)
+	}
+	accounts := chart{Type: "line", Title: chartTitle{Name: "Findings per account"}}
+	for i := range ids {
+		accounts.Series = append(accounts.Series, chartSeries{
+			Name:       chartRange(sheetName, i+2, 1, 1),
+			Categories: chartRange(sheetName, 1, 2, lastRow),
+			Values:     chartRange(sheetName, i+2, 2, lastRow),
+		})
+	}
+	chartCol, _ = excelize.ColumnNumberToName(len(header) + 2)
+	if err := addChart(xlsx, sheetName, chartCol+"1", accounts); err != nil {
+		fmt.Println("failed to add chart:", err)
+	}
+}
+
+func generateTrendSpreadsheet(t trend, outputDir string) (string, error) {
+	xlsx := excelize.NewFile()
+	styles := newSpreadsheetStyles(xlsx)
+	xlsx.SetSheetName(xlsx.GetSheetName(1), trendSheetName)
+	addTrendSheets(xlsx, styles, t)
+	xlsx.SetActiveSheet(xlsx.GetSheetIndex(trendSheetName))
+	path := getOutputPath(outputDir, "inspector_trend", t.GeneratedAt, FormatXLSX)
+	if err := xlsx.SaveAs(path); err != nil {
+		return "", errors.WithStack(err)
+	}
+	absPath, _ := filepath.Abs(path)
+	fmt.Println("report written to:", absPath)
+	return absPath, nil
+}
+
+func generateTrendJSON(t trend, outputDir string) (string, error) {
+	content, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	path := getOutputPath(outputDir, "inspector_trend", t.GeneratedAt, FormatJSON)
+	if err = ioutil.WriteFile(path, content, 0644); err != nil {
+		return "", errors.WithStack(err)
+	}
+	absPath, _ := filepath.Abs(path)
+	fmt.Println("report written to:", absPath)
+	return absPath, nil
+}
+
+// loadSnapshots reads every JSON report in the path, skipping any that are not reports
+func loadSnapshots(path string) (snapshots []snapshot, err error) {
+	paths, err := listFiles(path, FormatJSON)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		content, found, readErr := readFile(p)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if !found {
+			continue
+		}
+		var s snapshot
+		if err = json.Unmarshal(content, &s); err != nil || s.SchemaVersion == 0 {
+			fmt.Printf("skipping %s as it is not a JSON report\n", p)
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+// Trend generates a report of how findings have changed over time from the JSON reports of previous runs
+func Trend(config TrendConfig) error {
+	period := config.Period
+	if period == "" {
+		period = TrendPeriodMonth
+	}
+	if period != TrendPeriodMonth && period != TrendPeriodWeek {
+		return fmt.Errorf("trend period '%s' not supported, valid periods are: %s, %s", config.Period, TrendPeriodMonth, TrendPeriodWeek)
+	}
+	snapshots, err := loadSnapshots(config.SnapshotsPath)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return fmt.Errorf("no JSON reports found in %s", config.SnapshotsPath)
+	}
+	t := generateTrend(snapshots, period, time.Now().UTC())
+	if _, err = generateTrendSpreadsheet(t, config.OutputDir); err != nil {
+		return err
+	}
+	_, err = generateTrendJSON(t, config.OutputDir)
+	return err
+}
@@ -0,0 +1,113 @@
+package air
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSnapshots() []snapshot {
+	first := testAccountsResults()
+	// one finding resolved and one opened
+	second := testAccountsResults()
+	second[0].regionResults[0].regionTemplateResults[0].runs[0].findings = second[0].regionResults[0].regionTemplateResults[0].runs[0].findings[1:]
+	second[1].regionResults[0].regionTemplateResults[0].runs[0].findings = append(second[1].regionResults[0].regionTemplateResults[0].runs[0].findings,
+		testFinding("CVE-2019-0003", "Medium", "i-0000000003", "api"))
+	// all acme-prod findings resolved and a suppressed finding that is not counted
+	third := testAccountsResults()
+	third[0].regionResults[0].regionTemplateResults[0].runs[0].findings = third[0].regionResults[0].regionTemplateResults[0].runs[0].findings[1:]
+	third[0].regionResults[0].regionTemplateResults[0].runs[0].findings[1].Severity = ptrToStr("ignore")
+	third[1].regionResults[0].regionTemplateResults[0].runs[0].findings = nil
+	// returned out of order as they would be when listed
+	return []snapshot{
+		newSnapshot(third, nil, time.Date(2019, 7, 15, 0, 0, 0, 0, time.UTC)),
+		newSnapshot(first, nil, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)),
+		newSnapshot(second, nil, time.Date(2019, 6, 11, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+func TestGenerateTrend(t *testing.T) {
+	tr := generateTrend(testSnapshots(), TrendPeriodMonth, time.Now())
+	assert.Equal(t, 3, tr.Snapshots)
+	assert.Len(t, tr.Periods, 2)
+
+	june := tr.Periods[0]
+	assert.Equal(t, "2019-06", june.Period)
+	assert.Equal(t, time.Date(2019, 6, 11, 0, 0, 0, 0, time.UTC), june.SnapshotAt)
+	assert.Equal(t, map[string]int{"HIGH": 1, "MEDIUM": 2, "LOW": 1}, june.Severities)
+	assert.Equal(t, 4, june.Total)
+	assert.Equal(t, 1, june.Opened)
+	assert.Equal(t, 1, june.Closed)
+	assert.Equal(t, 10.0, june.MeanTimeToRemediateDays)
+	assert.Equal(t, 2, june.Accounts[0].Total)
+
+	july := tr.Periods[1]
+	assert.Equal(t, "2019-07", july.Period)
+	assert.Equal(t, map[string]int{"HIGH": 1}, july.Severities)
+	assert.Equal(t, 0, july.Opened)
+	// the suppressed finding and both acme-prod findings are no longer counted
+	assert.Equal(t, 3, july.Closed)
+	assert.Equal(t, 0, july.Accounts[1].Total)
+	assert.Equal(t, 40.7, july.MeanTimeToRemediateDays)
+	assert.Equal(t, 33.0, tr.MeanTimeToRemediateDays)
+
+	tr = generateTrend(testSnapshots(), TrendPeriodWeek, time.Now())
+	assert.Len(t, tr.Periods, 3)
+	assert.Equal(t, "2019-W22", tr.Periods[0].Period)
+}
+
+func TestGenerateTrendCollectionErrors(t *testing.T) {
+	first := newSnapshot(testAccountsResults(), nil, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	// findings couldn't be retrieved from acme-prod, so its findings are carried over rather than closed
+	ar := testAccountsResults()
+	tems := targetErrorsMaps{{target: target{ID: ar[1].accountID, Alias: ar[1].accountAlias},
+		errors: []annotatedError{{err: errors.New("AccessDenied"), desc: "failed to assume role"}}}}
+	second := newSnapshot(ar[:1], tems, time.Date(2019, 6, 8, 0, 0, 0, 0, time.UTC))
+
+	tr := generateTrend([]snapshot{first, second}, TrendPeriodMonth, time.Now())
+	assert.Len(t, tr.Periods, 1)
+	assert.Equal(t, 0, tr.Periods[0].Opened)
+	assert.Equal(t, 0, tr.Periods[0].Closed)
+	assert.Equal(t, 0.0, tr.MeanTimeToRemediateDays)
+	assert.Equal(t, generateTrend([]snapshot{first}, TrendPeriodMonth, time.Now()).Periods[0].Total, tr.Periods[0].Total)
+	assert.Len(t, tr.Periods[0].Accounts, 2)
+	assert.Equal(t, "acme-prod", tr.Periods[0].Accounts[1].Alias)
+	assert.NotZero(t, tr.Periods[0].Accounts[1].Total)
+}
+
+func TestTrend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, s := range testSnapshots() {
+		_, err = generateJSON(s.accountsResults(), nil, dir, s.GeneratedAt)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"name": "other"}`), 0644))
+
+	output := filepath.Join(dir, "output")
+	assert.NoError(t, os.Mkdir(output, 0755))
+	assert.NoError(t, Trend(TrendConfig{SnapshotsPath: dir, OutputDir: output}))
+	files, err := filepath.Glob(filepath.Join(output, "inspector_trend_*"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	xlsx, err := excelize.OpenFile(files[1])
+	assert.NoError(t, err)
+	assert.Equal(t, trendSheetName, xlsx.GetSheetName(1))
+	assert.Equal(t, trendAccountsSheetName, xlsx.GetSheetName(2))
+	value, _ := xlsx.GetCellValue(trendSheetName, "A3")
+	assert.Equal(t, "2019-07", value)
+	value, _ = xlsx.GetCellValue(trendAccountsSheetName, "C1")
+	assert.Equal(t, "acme-prod", value)
+
+	assert.Error(t, Trend(TrendConfig{SnapshotsPath: dir, Period: "year"}))
+	assert.Error(t, Trend(TrendConfig{SnapshotsPath: output}))
+}
@@ -30,6 +30,15 @@ func deleteFile(path string) (err error) {
 	err = os.Remove(path)
 	return
 }
+
+func deleteFiles(paths []string) (err error) {
+	for _, path := range paths {
+		if delErr := deleteFile(path); delErr != nil {
+			err = delErr
+		}
+	}
+	return
+}
 func padToWidth(input string, trimToWidth bool) (output string) {
 	// Split string into lines
 	char := " "
@@ -74,7 +83,7 @@ func padToWidth(input string, trimToWidth bool) (output string) {
 }
 func outputError(err error) {
 	output := padToWidth(fmt.Sprintf("error: %v\n", err), false)
-	_, _ = fmt.Fprintf(os.Stderr, output)
+	_, _ = fmt.Fprint(os.Stderr, output)
 }
 
 func getAccountID(svc stsiface.STSAPI) (id string) {
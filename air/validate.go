@@ -0,0 +1,327 @@
+package air
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// filterSeverities are the severities a filter can assign to a finding
+var filterSeverities = []string{"critical", "high", "medium", "low", "informational", "ignore"}
+
+var accountIDRegex = regexp.MustCompile(`^\d{12}$`)
+
+// organizationalUnitIDRegex matches the id of an organizational unit or the root of an organization
+var organizationalUnitIDRegex = regexp.MustCompile(`^(ou-[0-9a-z]{4,32}-[a-z0-9]{8,32}|r-[0-9a-z]{4,32})$`)
+
+// configProblem describes an issue found in a configuration file
+type configProblem struct {
+	file    string
+	line    int
+	message string
+}
+
+func (p configProblem) String() string {
+	if p.line == 0 {
+		return fmt.Sprintf("%s: %s", p.file, p.message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.file, p.line, p.message)
+}
+
+// configValidator checks the parsed root node of a configuration file
+type configValidator func(file string, root *yaml.Node) []configProblem
+
+// Validate loads the targets, filters, report and organization configuration files and reports every problem found
+// an error is returned if any problems are found
+func Validate(appConfig AppConfig) error {
+	var problems []configProblem
+	for _, cf := range []struct {
+		name     string
+		validate configValidator
+	}{
+		{targetsFileName, validateTargets},
+		{filtersFileName, validateFilters},
+		{reportFileName, validateReport},
+		{organizationFileName, validateOrganization},
+	} {
+		location, content, found, err := readConfigFile(appConfig.ConfigPath, cf.name)
+		if err != nil {
+			return err
+		}
+		if !found {
+			fmt.Printf("%s: not found\n", location)
+			continue
+		}
+		fileProblems := validateConfigContent(location, content, cf.validate)
+		if len(fileProblems) == 0 {
+			fmt.Printf("%s: ok\n", location)
+		}
+		problems = append(problems, fileProblems...)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found in configuration", len(problems))
+	}
+	return nil
+}
+
+func validateConfigContent(file string, content []byte, validate configValidator) []configProblem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return []configProblem{{file: file, message: err.Error()}}
+	}
+	// an empty file contains no configuration to check
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	problems := validate(file, doc.Content[0])
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].line < problems[j].line
+	})
+	return problems
+}
+
+// mappingValues returns the value nodes of a mapping node keyed by name
+func mappingValues(node *yaml.Node) map[string]*yaml.Node {
+	values := make(map[string]*yaml.Node)
+	if node.Kind != yaml.MappingNode {
+		return values
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		values[node.Content[i].Value] = node.Content[i+1]
+	}
+	return values
+}
+
+// yamlKey returns the key used for a struct field, which is the field name in lower case if no tag is set
+func yamlKey(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("yaml"), ",")[0]; tag != "" {
+		return tag
+	}
+	return strings.ToLower(field.Name)
+}
+
+// unknownKeys reports keys in the mapping node, and any nested mappings, that are not fields of the type
+func unknownKeys(file string, node *yaml.Node, t reflect.Type) (problems []configProblem) {
+	if node.Kind != yaml.MappingNode || t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make(map[string]reflect.Type)
+	var known []string
+	for i := 0; i < t.NumField(); i++ {
+		key := yamlKey(t.Field(i))
+		fields[key] = t.Field(i).Type
+		known = append(known, key)
+	}
+	sort.Strings(known)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fieldType, ok := fields[key.Value]
+		if !ok {
+			problems = append(problems, configProblem{file: file, line: key.Line,
+				message: fmt.Sprintf("unknown key '%s', expected one of: %s", key.Value, strings.Join(known, ", "))})
+			continue
+		}
+		switch {
+		case fieldType.Kind() == reflect.Struct:
+			problems = append(problems, unknownKeys(file, value, fieldType)...)
+		case fieldType.Kind() == reflect.Slice && value.Kind == yaml.SequenceNode:
+			for _, item := range value.Content {
+				problems = append(problems, unknownKeys(file, item, fieldType.Elem())...)
+			}
+		}
+	}
+	return problems
+}
+
+// sequenceItems returns the items of a list, reporting a problem if the node is not a list
+func sequenceItems(file string, root *yaml.Node) ([]*yaml.Node, []configProblem) {
+	if root.Kind != yaml.SequenceNode {
+		return nil, []configProblem{{file: file, line: root.Line, message: "expected a list"}}
+	}
+	return root.Content, nil
+}
+
+func validateTargets(file string, root *yaml.Node) []configProblem {
+	items, problems := sequenceItems(file, root)
+	seen := make(map[string]int)
+	for _, item := range items {
+		problems = append(problems, unknownKeys(file, item, reflect.TypeOf(target{}))...)
+		values := mappingValues(item)
+		if version, ok := values["inspector"]; ok {
+			if _, err := inspectorVersion(version.Value); err != nil {
+				problems = append(problems, configProblem{file: file, line: version.Line, message: err.Error()})
+			}
+		}
+		if partition, ok := values["partition"]; ok {
+			if _, err := partitionID(partition.Value); err != nil {
+				problems = append(problems, configProblem{file: file, line: partition.Line, message: err.Error()})
+			}
+		}
+		if regions, ok := values["regions"]; ok {
+			for _, region := range regions.Content {
+				if err := checkRegion(region.Value); err != nil {
+					problems = append(problems, configProblem{file: file, line: region.Line, message: err.Error()})
+				}
+			}
+		}
+		id, ok := values["id"]
+		if !ok {
+			problems = append(problems, configProblem{file: file, line: item.Line, message: "target id not specified"})
+			continue
+		}
+		if !accountIDRegex.MatchString(id.Value) {
+			problems = append(problems, configProblem{file: file, line: id.Line,
+				message: fmt.Sprintf("malformed account id '%s', expected 12 digits", id.Value)})
+		}
+		if line, dup := seen[id.Value]; dup {
+			problems = append(problems, configProblem{file: file, line: id.Line,
+				message: fmt.Sprintf("duplicate target '%s', first defined on line %d", id.Value, line)})
+			continue
+		}
+		seen[id.Value] = id.Line
+	}
+	return problems
+}
+
+func validateFilters(file string, root *yaml.Node) []configProblem {
+	items, problems := sequenceItems(file, root)
+	seen := make(map[string]int)
+	for _, item := range items {
+		problems = append(problems, unknownKeys(file, item, reflect.TypeOf(filter{}))...)
+		var f filter
+		if err := item.Decode(&f); err != nil {
+			problems = append(problems, configProblem{file: file, line: item.Line, message: err.Error()})
+			continue
+		}
+		values := mappingValues(item)
+		lineOf := func(key string) int {
+			if v, ok := values[key]; ok {
+				return v.Line
+			}
+			return item.Line
+		}
+		criteria := f.criteria()
+		if len(criteria) == 0 {
+			problems = append(problems, configProblem{file: file, line: item.Line, message: "filter has no match criteria so will never apply"})
+		}
+		for _, p := range f.patterns() {
+			if _, err := regexp.Compile(p.expression); err != nil {
+				problems = append(problems, configProblem{file: file, line: lineOf(p.key),
+					message: fmt.Sprintf("invalid regular expression in %s: %s", p.key, err)})
+			}
+		}
+		if !stringInSlice(strings.ToLower(f.Severity), filterSeverities) {
+			problems = append(problems, configProblem{file: file, line: lineOf("severity"),
+				message: fmt.Sprintf("unknown severity '%s', expected one of: %s", f.Severity, strings.Join(filterSeverities, ", "))})
+		}
+		if _, err := f.expiry(); err != nil {
+			problems = append(problems, configProblem{file: file, line: lineOf("expires"),
+				message: fmt.Sprintf("invalid expiry date '%s', expected YYYY-MM-DD", f.Expires)})
+		}
+		if len(criteria) == 0 {
+			continue
+		}
+		key := strings.Join(criteria, "\n")
+		if line, dup := seen[key]; dup {
+			problems = append(problems, configProblem{file: file, line: item.Line,
+				message: fmt.Sprintf("duplicate filter, criteria match the filter on line %d", line)})
+			continue
+		}
+		seen[key] = item.Line
+	}
+	return problems
+}
+
+func validateReport(file string, root *yaml.Node) []configProblem {
+	if root.Kind != yaml.MappingNode {
+		return []configProblem{{file: file, line: root.Line, message: "expected a mapping"}}
+	}
+	problems := unknownKeys(file, root, reflect.TypeOf(Report{}))
+	var report Report
+	if err := root.Decode(&report); err != nil {
+		return append(problems, configProblem{file: file, line: root.Line, message: err.Error()})
+	}
+	values := mappingValues(root)
+	if email, ok := values["email"]; ok {
+		if err := validateEmailSettings(report.Email); err != nil {
+			problems = append(problems, configProblem{file: file, line: email.Line, message: err.Error()})
+		}
+	}
+	if spreadsheet, ok := values["spreadsheet"]; ok {
+		// unknown severities are reported as unknown keys
+		if sla, ok := mappingValues(spreadsheet)["sla"]; ok && sla.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(sla.Content); i += 2 {
+				var days int
+				if err := sla.Content[i+1].Decode(&days); err == nil && days < 0 {
+					problems = append(problems, configProblem{file: file, line: sla.Content[i+1].Line,
+						message: fmt.Sprintf("sla %s must not be negative", sla.Content[i].Value)})
+				}
+			}
+		}
+		if columns, ok := mappingValues(spreadsheet)["columns"]; ok && columns.Kind == yaml.SequenceNode {
+			for i, column := range columns.Content {
+				if i >= len(report.Spreadsheet.Columns) {
+					break
+				}
+				if _, err := resolveColumns(report.Spreadsheet.Columns[i : i+1]); err != nil {
+					line := column.Line
+					if field, ok := mappingValues(column)["field"]; ok {
+						line = field.Line
+					}
+					problems = append(problems, configProblem{file: file, line: line, message: err.Error()})
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func validateOrganization(file string, root *yaml.Node) []configProblem {
+	if root.Kind != yaml.MappingNode {
+		return []configProblem{{file: file, line: root.Line, message: "expected a mapping"}}
+	}
+	problems := unknownKeys(file, root, reflect.TypeOf(organization{}))
+	values := mappingValues(root)
+	if roleName, ok := values["roleName"]; !ok || roleName.Value == "" {
+		problems = append(problems, configProblem{file: file, line: root.Line, message: "roleName not specified"})
+	}
+	for _, key := range []string{"include", "exclude"} {
+		selector, ok := values[key]
+		if !ok {
+			continue
+		}
+		selectorValues := mappingValues(selector)
+		if accounts, ok := selectorValues["accounts"]; ok {
+			for _, id := range accounts.Content {
+				if !accountIDRegex.MatchString(id.Value) {
+					problems = append(problems, configProblem{file: file, line: id.Line,
+						message: fmt.Sprintf("malformed account id '%s', expected 12 digits", id.Value)})
+				}
+			}
+		}
+		if ous, ok := selectorValues["ous"]; ok {
+			for _, ou := range ous.Content {
+				if !organizationalUnitIDRegex.MatchString(ou.Value) {
+					problems = append(problems, configProblem{file: file, line: ou.Line,
+						message: fmt.Sprintf("malformed organizational unit id '%s', expected ou-xxxx-xxxxxxxx or r-xxxx", ou.Value)})
+				}
+			}
+		}
+		if tags, ok := selectorValues["tags"]; ok {
+			for _, tag := range tags.Content {
+				if strings.SplitN(tag.Value, "=", 2)[0] == "" {
+					problems = append(problems, configProblem{file: file, line: tag.Line,
+						message: fmt.Sprintf("malformed tag '%s', expected <key>=<value> or <key>", tag.Value)})
+				}
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,146 @@
+package air
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTargets(t *testing.T) {
+	content := []byte(`---
+- id: 01234567890
+  alias: acme-nonprod
+- id: 987654321098
+  rolename: InspectorScan
+- id: 987654321098
+- alias: acme-dev
+  inspector: v3
+  regions:
+    - eu-west-1
+    - eu-west-9
+  partition: aws-iso
+`)
+	problems := validateConfigContent("targets.yml", content, validateTargets)
+	assert.Equal(t, []configProblem{
+		{file: "targets.yml", line: 2, message: "malformed account id '01234567890', expected 12 digits"},
+		{file: "targets.yml", line: 5, message: "unknown key 'rolename', expected one of: alias, id, inspector, partition, regions, roleExternalId, roleName"},
+		{file: "targets.yml", line: 6, message: "duplicate target '987654321098', first defined on line 4"},
+		{file: "targets.yml", line: 7, message: "target id not specified"},
+		{file: "targets.yml", line: 8, message: "inspector version 'v3' not supported, valid versions are: classic, v2, all"},
+		{file: "targets.yml", line: 11, message: "region 'eu-west-9' not recognised"},
+		{file: "targets.yml", line: 12, message: "partition 'aws-iso' not supported, valid partitions are: aws, aws-us-gov, aws-cn"},
+	}, problems)
+}
+
+func TestValidateFilters(t *testing.T) {
+	content := []byte(`---
+- title-match: "^1.4.2 (Ensure"
+  severity: ignore
+- title-match: ^CVE
+  severity: urgent
+  expire: 2019-01-01
+- title-match: ^CVE
+  severity: low
+  expires: 30/09/2019
+- rules-package-match: ^Common
+  tag-match:
+    - Environment=[dev
+  severity: low
+- severity: low
+`)
+	problems := validateConfigContent("filters.yml", content, validateFilters)
+	var lines []int
+	for _, p := range problems {
+		lines = append(lines, p.line)
+	}
+	assert.Equal(t, []int{2, 5, 6, 7, 9, 12, 14}, lines)
+	assert.Contains(t, problems[0].message, "invalid regular expression in title-match")
+	assert.Contains(t, problems[1].message, "unknown severity 'urgent'")
+	assert.Contains(t, problems[2].message, "unknown key 'expire'")
+	assert.Equal(t, "duplicate filter, criteria match the filter on line 4", problems[3].message)
+	assert.Contains(t, problems[4].message, "invalid expiry date")
+	assert.Contains(t, problems[5].message, "invalid regular expression in tag-match")
+	assert.Equal(t, "filter has no match criteria so will never apply", problems[6].message)
+
+	content, err := ioutil.ReadFile(filepath.Join("..", "docs", "filters.yml.example"))
+	assert.NoError(t, err)
+	assert.Empty(t, validateConfigContent("filters.yml", content, validateFilters))
+}
+
+func TestValidateReport(t *testing.T) {
+	content := []byte(`---
+email:
+  provider: ses
+  source: inspector
+  subjct: Inspector Reports
+spreadsheet:
+  columns:
+    - field: severity
+    - field: unknown
+  sla:
+    critical: -7
+    urgent: 1
+`)
+	problems := validateConfigContent("report.yml", content, validateReport)
+	assert.Len(t, problems, 5)
+	assert.Equal(t, configProblem{file: "report.yml", line: 3, message: "invalid email address 'inspector'"}, problems[0])
+	assert.Equal(t, 5, problems[1].line)
+	assert.Contains(t, problems[1].message, "unknown key 'subjct'")
+	assert.Equal(t, 9, problems[2].line)
+	assert.Equal(t, configProblem{file: "report.yml", line: 11, message: "sla critical must not be negative"}, problems[3])
+	assert.Equal(t, 12, problems[4].line)
+	assert.Contains(t, problems[4].message, "unknown key 'urgent'")
+
+	content, err := ioutil.ReadFile(filepath.Join("..", "docs", "report.yml.example"))
+	assert.NoError(t, err)
+	assert.Empty(t, validateConfigContent("report.yml", content, validateReport))
+
+	assert.Len(t, validateConfigContent("report.yml", []byte("email: [\n"), validateReport), 1)
+}
+
+func TestValidateOrganization(t *testing.T) {
+	content := []byte(`---
+roleExternalId: somethingrandom
+include:
+  ous:
+    - ou-ab12
+  tag:
+    - inspector=enabled
+exclude:
+  accounts:
+    - 12345
+  tags:
+    - =sandbox
+`)
+	problems := validateConfigContent("organization.yml", content, validateOrganization)
+	assert.Equal(t, []configProblem{
+		{file: "organization.yml", line: 2, message: "roleName not specified"},
+		{file: "organization.yml", line: 5, message: "malformed organizational unit id 'ou-ab12', expected ou-xxxx-xxxxxxxx or r-xxxx"},
+		{file: "organization.yml", line: 6, message: "unknown key 'tag', expected one of: accounts, ous, tags"},
+		{file: "organization.yml", line: 10, message: "malformed account id '12345', expected 12 digits"},
+		{file: "organization.yml", line: 12, message: "malformed tag '=sandbox', expected <key>=<value> or <key>"},
+	}, problems)
+
+	content, err := ioutil.ReadFile(filepath.Join("..", "docs", "organization.yml.example"))
+	assert.NoError(t, err)
+	assert.Empty(t, validateConfigContent("organization.yml", content, validateOrganization))
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "air")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{targetsFileName, filtersFileName, reportFileName, organizationFileName} {
+		content, readErr := ioutil.ReadFile(filepath.Join("..", "docs", name+".example"))
+		assert.NoError(t, readErr)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), content, 0644))
+	}
+	assert.NoError(t, Validate(AppConfig{ConfigPath: dir}))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filtersFileName), []byte("- title-match: \"(\"\n  severity: low\n"), 0644))
+	assert.Error(t, Validate(AppConfig{ConfigPath: dir}))
+}
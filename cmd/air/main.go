@@ -11,6 +11,13 @@ import (
 	"github.com/urfave/cli"
 )
 
+// exit codes returned when air.Run fails
+const (
+	exitCodeError             = 1
+	exitCodeSeverityThreshold = 2
+	exitCodeCollectionErrors  = 3
+)
+
 // overwritten at build time
 var version, versionOutput, tag, sha, buildDate string
 
@@ -49,22 +56,136 @@ func startCLI(args []string) (msg string, display bool, err error) {
 	app.Usage = "AWS Inspector Reporter"
 	app.Description = ""
 
-	app.Flags = []cli.Flag{
-		cli.StringFlag{Name: "config-path", Usage: "load configuration files from filesystem path or AWS S3 using s3://...", Value: "config/"},
-		cli.StringFlag{Name: "output", Usage: "report output directory"},
-		cli.IntFlag{Name: "max-report-age", Usage: "max age (in days) of reports to check", Value: air2.DefaultMaxReportAge},
-		cli.BoolFlag{Name: "debug"},
+	app.Flags = reportFlags
+
+	app.Commands = []cli.Command{
+		{
+			Name:  "validate",
+			Usage: "check the targets, filters and report configuration files for problems",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "config-path", Usage: "load configuration files from filesystem path or AWS S3 using s3://...", Value: "config/"},
+			},
+			Action: func(c *cli.Context) error {
+				return air2.Validate(air2.AppConfig{ConfigPath: options{c}.String("config-path")})
+			},
+		},
+		{
+			Name:  "report",
+			Usage: "generate reports from a JSON report saved by a previous run instead of retrieving findings from Inspector",
+			Flags: append([]cli.Flag{
+				cli.StringFlag{Name: "from", Usage: "local path or s3://bucket/key of the JSON report"},
+			}, reportFlags...),
+			Action: func(c *cli.Context) error {
+				if c.String("from") == "" {
+					return cli.NewExitError("error: --from must be specified", exitCodeError)
+				}
+				return runReport(c)
+			},
+		},
+		{
+			Name:  "trend",
+			Usage: "generate a report of how findings have changed over time from the JSON reports of previous runs",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "snapshots", Usage: "directory or s3://bucket/prefix containing JSON reports"},
+				cli.StringFlag{Name: "period", Usage: "period to group reports by: month, week", Value: air2.TrendPeriodMonth},
+				cli.StringFlag{Name: "output", Usage: "report output directory"},
+			},
+			Action: func(c *cli.Context) error {
+				if c.String("snapshots") == "" {
+					return cli.NewExitError("error: --snapshots must be specified", exitCodeError)
+				}
+				return air2.Trend(air2.TrendConfig{
+					SnapshotsPath: c.String("snapshots"),
+					Period:        c.String("period"),
+					OutputDir:     strings.Trim(c.String("output"), " "),
+				})
+			},
+		},
 	}
 
-	app.Action = func(c *cli.Context) error {
-		_ = air2.Run(air2.AppConfig{
-			Debug:        c.Bool("debug"),
-			ConfigPath:   c.String("config-path"),
-			MaxReportAge: c.Int("max-report-age"),
-			OutputDir:    strings.Trim(c.String("output"), " "),
-		})
+	app.Action = runReport
+	return msg, display, app.Run(args)
+}
 
+var reportFlags = []cli.Flag{
+	cli.StringFlag{Name: "config-path", Usage: "load configuration files from filesystem path or AWS S3 using s3://...", Value: "config/"},
+	cli.StringFlag{Name: "output", Usage: "report output directory"},
+	cli.StringFlag{Name: "format", Usage: "comma separated list of report formats: xlsx, json, csv, html", Value: air2.FormatXLSX},
+	cli.IntFlag{Name: "max-report-age", Usage: "max age (in days) of reports to check", Value: air2.DefaultMaxReportAge},
+	cli.StringFlag{Name: "state", Usage: "local path or s3://bucket/key of a file used to compare findings with those of the previous run"},
+	cli.StringFlag{Name: "fail-on", Usage: "exit with status 2 if any findings remaining after filtering are at or above this severity: critical, high, medium, low, informational"},
+	cli.StringFlag{Name: "inspector", Usage: "version of Inspector to retrieve findings from: classic, v2 or all", Value: air2.InspectorClassic},
+	cli.IntFlag{Name: "account-concurrency", Usage: "maximum number of accounts to retrieve findings from at the same time", Value: air2.DefaultAccountConcurrency},
+	cli.IntFlag{Name: "region-concurrency", Usage: "maximum number of regions to retrieve findings from at the same time within each account", Value: air2.DefaultRegionConcurrency},
+	cli.StringFlag{Name: "regions", Usage: "comma separated list of regions to retrieve findings from (default: all regions Inspector is available in)"},
+	cli.StringFlag{Name: "exclude-regions", Usage: "comma separated list of regions to not retrieve findings from"},
+	cli.StringFlag{Name: "partition", Usage: "partition of the targets: aws, aws-us-gov or aws-cn", Value: air2.PartitionAWS},
+	cli.StringFlag{Name: "partition-profiles", Usage: "comma separated list of <partition>=<profile> with the AWS profile to use for targets in each partition"},
+	cli.IntFlag{Name: "expiry-warning-days", Usage: "warn about filters that expire within this number of days", Value: air2.DefaultExpiryWarningDays},
+	cli.BoolFlag{Name: "debug", Usage: "show the stack trace of each error encountered retrieving findings"},
+}
+
+// options reads the flags of a command, falling back to those of the root command that are only set there
+// so options given before the command, e.g. air --format csv report --from ..., aren't ignored
+type options struct {
+	c *cli.Context
+}
+
+func (o options) global(name string) bool {
+	return o.c.GlobalIsSet(name) && !o.c.IsSet(name)
+}
+
+func (o options) String(name string) string {
+	if o.global(name) {
+		return o.c.GlobalString(name)
+	}
+	return o.c.String(name)
+}
+
+func (o options) Int(name string) int {
+	if o.global(name) {
+		return o.c.GlobalInt(name)
+	}
+	return o.c.Int(name)
+}
+
+func (o options) Bool(name string) bool {
+	if o.global(name) {
+		return o.c.GlobalBool(name)
+	}
+	return o.c.Bool(name)
+}
+
+// runReport generates reports using the options of the root command or the report command
+func runReport(c *cli.Context) error {
+	o := options{c}
+	err := air2.Run(air2.AppConfig{
+		Debug:              o.Bool("debug"),
+		ConfigPath:         o.String("config-path"),
+		MaxReportAge:       o.Int("max-report-age"),
+		OutputDir:          strings.Trim(o.String("output"), " "),
+		Formats:            strings.Split(o.String("format"), ","),
+		Version:            versionOutput,
+		ExpiryWarningDays:  o.Int("expiry-warning-days"),
+		StatePath:          o.String("state"),
+		FailOn:             o.String("fail-on"),
+		Inspector:          o.String("inspector"),
+		AccountConcurrency: o.Int("account-concurrency"),
+		RegionConcurrency:  o.Int("region-concurrency"),
+		Regions:            strings.Split(o.String("regions"), ","),
+		ExcludeRegions:     strings.Split(o.String("exclude-regions"), ","),
+		Partition:          o.String("partition"),
+		PartitionProfiles:  strings.Split(o.String("partition-profiles"), ","),
+		FromSnapshot:       c.String("from"),
+	})
+	switch err.(type) {
+	case nil:
 		return nil
+	case air2.SeverityThresholdError:
+		return cli.NewExitError(err.Error(), exitCodeSeverityThreshold)
+	case air2.CollectionError:
+		return cli.NewExitError(err.Error(), exitCodeCollectionErrors)
+	default:
+		return cli.NewExitError(fmt.Sprintf("error: %+v", err), exitCodeError)
 	}
-	return msg, display, app.Run(args)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	air2 "github.com/jonhadfield/aws-inspector-reporter/air"
 
@@ -17,11 +18,11 @@ import (
 var version, tag, sha, buildDate string
 
 func Handler(cwe events.CloudWatchEvent) error {
+	versionOutput := version
 	if tag != "" && buildDate != "" {
-		fmt.Printf("version [%s-%s] %s UTC\n", tag, sha, buildDate)
-	} else {
-		fmt.Println("version", version)
+		versionOutput = fmt.Sprintf("[%s-%s] %s UTC", tag, sha, buildDate)
 	}
+	fmt.Println("version", versionOutput)
 	log.Printf("Processing Lambda cwe: %s\n", cwe.Time)
 	var debug bool
 	if os.Getenv("AIR_DEBUG") != "" {
@@ -36,16 +37,46 @@ func Handler(cwe events.CloudWatchEvent) error {
 		}
 	}
 
+	expiryWarningDays := air2.DefaultExpiryWarningDays
+	if os.Getenv("AIR_EXPIRY_WARNING_DAYS") != "" {
+		expiryWarningDays, err = strconv.Atoi(os.Getenv("AIR_EXPIRY_WARNING_DAYS"))
+		if err != nil {
+			expiryWarningDays = air2.DefaultExpiryWarningDays
+		}
+	}
+
+	// unset or invalid values use the default concurrency
+	accountConcurrency, _ := strconv.Atoi(os.Getenv("AIR_ACCOUNT_CONCURRENCY"))
+	regionConcurrency, _ := strconv.Atoi(os.Getenv("AIR_REGION_CONCURRENCY"))
+
 	err = air2.Run(air2.AppConfig{
-		Debug:        debug,
-		ConfigPath:   os.Getenv("AIR_CONFIG_PATH"),
-		MaxReportAge: maxReportAge,
-		OutputDir:    "/tmp",
+		Debug:              debug,
+		ConfigPath:         os.Getenv("AIR_CONFIG_PATH"),
+		MaxReportAge:       maxReportAge,
+		OutputDir:          "/tmp",
+		Formats:            strings.Split(os.Getenv("AIR_FORMAT"), ","),
+		Version:            versionOutput,
+		ExpiryWarningDays:  expiryWarningDays,
+		StatePath:          os.Getenv("AIR_STATE_PATH"),
+		Inspector:          os.Getenv("AIR_INSPECTOR"),
+		AccountConcurrency: accountConcurrency,
+		RegionConcurrency:  regionConcurrency,
+		Regions:            strings.Split(os.Getenv("AIR_REGIONS"), ","),
+		ExcludeRegions:     strings.Split(os.Getenv("AIR_EXCLUDE_REGIONS"), ","),
+		Partition:          os.Getenv("AIR_PARTITION"),
 	})
-	if err != nil {
+	switch err.(type) {
+	case nil:
+		return nil
+	case air2.CollectionError:
+		// the report has been generated and emailed with the errors included, so don't fail
+		// the invocation as asynchronous invocations are retried and the report would be sent again
+		log.Printf("warning: %s\n", err)
+		return nil
+	default:
 		log.Printf("error: %+v\n", err)
+		return err
 	}
-	return err
 }
 
 func main() {